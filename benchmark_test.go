@@ -246,3 +246,7 @@ func BenchmarkFileSortLinesI(b *testing.B) {
 func BenchmarkFileSortParagraphsI(b *testing.B) {
 	benchmarkFileProcFunc(b, getFileProcFunc(textproc.SortLFParagraphsI))
 }
+
+func BenchmarkFileSortTreeI(b *testing.B) {
+	benchmarkFileProcFunc(b, getFileProcFunc(textproc.SortLFTreeI))
+}
@@ -0,0 +1,59 @@
+package textproc
+
+import (
+	"fmt"
+	"io"
+)
+
+// A Position is a location in the text.
+type Position struct {
+	// Line and Column count from 1.
+	// Column counts runes.
+	Line, Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprint(p.Line, ":", p.Column)
+}
+
+// firstDifference returns the position in a of the first difference
+// between a and b, or nil if they are identical.
+func firstDifference(a, b []rune) *Position {
+	pos := Position{1, 1}
+	for i := range a {
+		if i == len(b) || a[i] != b[i] {
+			return &pos
+		}
+		if a[i] == '\n' {
+			pos.Line, pos.Column = pos.Line+1, 1
+		} else {
+			pos.Column++
+		}
+	}
+	if len(b) > len(a) {
+		return &pos
+	}
+	return nil
+}
+
+// Check reads all runes from r, processes them with p
+// and compares the output with the input without writing anything.
+// It returns the position in the input of the first difference,
+// or nil if processing does not change the input.
+// It fails with ErrInvalidUTF8 if the input is not valid UTF-8.
+func Check(r io.Reader, p RuneProcessor) (*Position, error) {
+	var input []rune
+	runeCh, errCh := ReadRunes(r)
+	for char := range runeCh {
+		input = append(input, char)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+
+	output, err := processRunes(p, input)
+	if err != nil {
+		return nil, err
+	}
+	return firstDifference(input, output), nil
+}
@@ -0,0 +1,52 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	for in, want := range map[string]*struct {
+		pos *textproc.Position
+		err error
+	}{
+		"":            {nil, nil},
+		"a\nb\n":      {nil, nil},
+		"a\nb":        {&textproc.Position{2, 2}, nil},
+		"a\nbc \nd\n": {&textproc.Position{2, 3}, nil},
+		"\n\nx\n":     {&textproc.Position{1, 1}, nil},
+		"a\n\n":       {&textproc.Position{2, 1}, nil},
+		"a\n\xff":     {nil, textproc.ErrInvalidUTF8},
+	} {
+		pos, err := textproc.Check(strings.NewReader(in),
+			normalize)
+		if err != want.err {
+			t.Fatal("Want", want.err, "got", err)
+		}
+		if (pos == nil) != (want.pos == nil) ||
+			(pos != nil && *pos != *want.pos) {
+			t.Fatalf("%#v: want %v got %v", in, want.pos, pos)
+		}
+	}
+}
+
+func TestPositionString(t *testing.T) {
+	if got := (textproc.Position{3, 14}).String(); got != "3:14" {
+		t.Fatalf("Want %#v got %#v", "3:14", got)
+	}
+}
+
+func normalize(runeCh <-chan rune, errCh <-chan error) (
+	<-chan rune, <-chan error) {
+	for _, p := range []textproc.RuneProcessor{
+		textproc.ConvertLineTerminatorsToLF,
+		textproc.TrimLFTrailingWhiteSpace,
+		textproc.TrimLeadingEmptyLFLines,
+		textproc.TrimTrailingEmptyLFLines,
+		textproc.EnsureFinalLFIfNonEmpty,
+	} {
+		runeCh, errCh = p(runeCh, errCh)
+	}
+	return runeCh, errCh
+}
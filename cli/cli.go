@@ -0,0 +1,357 @@
+// Package cli implements the textproc command
+// on the processors of a registry,
+// so that programs can embed it with their own processors:
+//
+//	func main() {
+//		reg := registry.Builtin()
+//		reg.MustRegister("myproc", &registry.Processor{…})
+//		cli.Main(reg)
+//	}
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"os"
+	"strings"
+)
+
+var errNoProgramName = errors.New("no program name (os.Args empty)")
+
+// ExitChanged is the exit status for -check and -d
+// if processing changes the input,
+// and for -invisible if it finds invisible characters.
+const ExitChanged = 3
+
+// A flagError is an invalid command line flag,
+// which the flag package has already reported.
+type flagError struct {
+	error
+}
+
+type cmdArgs struct {
+	reg       *registry.Registry
+	runeProcs []textproc.RuneProcessor
+	check     bool
+	diff      bool
+	context   int
+	write     bool
+	backup    string
+	recursive bool
+	walker    *fileWalker
+	stats     bool
+	json      bool
+	invisible bool
+	allow     codePointsFlag
+	// editorConfig is not nil with -editorconfig.
+	editorConfig *editorConfig
+	// config is not nil with -config.
+	config *projectConfig
+	files  []string
+}
+
+// fileModes reports if the arguments select a mode which processes files.
+func (args *cmdArgs) fileModes() bool {
+	return args.check || args.diff || args.write || args.recursive ||
+		args.stats || args.invisible || args.editorConfig != nil || args.config != nil
+}
+
+// flagsEndedByDashDash reports if the flags parsed by fs from args
+// end with a "--" argument, and not a "--" flag value.
+func flagsEndedByDashDash(fs *flag.FlagSet, args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return true
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			return false
+		}
+		name := strings.TrimPrefix(arg[1:], "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok ||
+			!b.IsBoolFlag() {
+			// The next argument is the value of the flag.
+			i++
+		}
+	}
+	return false
+}
+
+// parseArgs parses the command line using the processors of reg.
+// It writes the usage and the flag errors to output.
+func parseArgs(reg *registry.Registry, osArgs []string, output io.Writer) (
+	*cmdArgs, error) {
+	if len(osArgs) == 0 {
+		return nil, errNoProgramName
+	}
+
+	fs := flag.NewFlagSet(osArgs[0], flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "usage: ", fs.Name(),
+			" [options] [processors] [--] [files]\n")
+		fmt.Fprint(fs.Output(), `
+Process text from stdin to stdout,
+or process the files with -check, -config, -d, -editorconfig,
+-invisible, -r, -stats or -w.
+The files start after "--" or at the first argument which cannot be
+a processor name (lowercase letters and digits), like a.txt or ./a.
+
+A processor is a name, optionally followed by parameters:
+name:param=value,param=value (escape commas in values as \,
+or double-quote values in Go syntax, e.g. prefix="> ").
+A boolean parameter without a value is true.
+
+processors:
+`)
+		for _, name := range reg.Names() {
+			p, _ := reg.Lookup(name)
+			fmt.Fprintf(fs.Output(), "\t%s\t%s\n", name, p.Doc)
+			for _, param := range p.Params {
+				fmt.Fprintf(fs.Output(), "\t\t%s\n", param.Usage())
+			}
+		}
+		fmt.Fprint(fs.Output(), "\noptional arguments:\n")
+		fs.PrintDefaults()
+	}
+	args := &cmdArgs{reg: reg}
+	fs.BoolVar(&args.check, "check", false, fmt.Sprint(
+		"write nothing, report where processing changes the input",
+		"\nand exit with status ", ExitChanged))
+	fs.BoolVar(&args.diff, "d", false, fmt.Sprint(
+		"write a unified diff between the input and the output",
+		"\nand exit with status ", ExitChanged, " if they differ"))
+	fs.IntVar(&args.context, "context", 3,
+		"with -d, the `number` of unchanged lines around each change")
+	fs.BoolVar(&args.write, "w", false,
+		"rewrite the files which processing changes")
+	fs.StringVar(&args.backup, "backup", "", "with -w, save the original"+
+		" content of the changed files\nto files with this name `suffix`")
+	args.walker = &fileWalker{}
+	fs.BoolVar(&args.recursive, "r", false, "process the text files"+
+		" in directories recursively,\nskipping binary and .gitignore'd files"+
+		"\n(.gitignore files above the directories are not read)")
+	fs.Var(&args.walker.include, "include", "with -r, only process files"+
+		" matching this `glob` (repeatable)")
+	fs.Var(&args.walker.exclude, "exclude", "with -r, skip files and"+
+		" directories matching this `glob` (repeatable)")
+	fs.BoolVar(&args.stats, "stats", false, "write statistics of the output"+
+		" instead of the output:\ncounts of bytes, runes, lines, words,"+
+		" line terminators\nand white space")
+	fs.BoolVar(&args.json, "json", false,
+		"with -stats, write a JSON object per input")
+	fs.BoolVar(&args.invisible, "invisible", false, fmt.Sprint(
+		"report the invisible and bidirectional control characters",
+		"\nin the output instead of the output",
+		"\nand exit with status ", ExitChanged, " if there are any"))
+	fs.Var(&args.allow, "allow", "with -invisible, do not report this"+
+		" `code point` U+XXXX (repeatable)")
+	useConfig := fs.Bool("config", false, "after the processors, apply"+
+		" to each file the processors\nof the nearest matching rule"+
+		" in its "+configFileName+" files")
+	useEditorConfig := fs.Bool("editorconfig", false, "after the processors,"+
+		" apply to each file the properties\nof its .editorconfig files")
+	if err := fs.Parse(osArgs[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return nil, err
+		}
+		return nil, flagError{err}
+	}
+
+	if args.check && args.write {
+		return nil, errors.New("-check and -w are mutually exclusive")
+	}
+	if args.check && args.diff {
+		return nil, errors.New("-check and -d are mutually exclusive")
+	}
+	if args.stats && (args.check || args.diff || args.write) {
+		return nil, errors.New(
+			"-stats and -check, -d or -w are mutually exclusive")
+	}
+	if args.invisible && (args.check || args.diff || args.write ||
+		args.stats) {
+		return nil, errors.New(
+			"-invisible and -check, -d, -stats or -w are mutually exclusive")
+	}
+	if len(args.allow) > 0 && !args.invisible {
+		return nil, errors.New("-allow requires -invisible")
+	}
+	if args.json && !args.stats {
+		return nil, errors.New("-json requires -stats")
+	}
+	if args.context < 0 {
+		return nil, errors.New("-context must not be negative")
+	}
+	if *useEditorConfig {
+		args.editorConfig = &editorConfig{reg: reg}
+	}
+	if *useConfig {
+		args.config = &projectConfig{reg: reg}
+	}
+
+	procArgs := fs.Args()
+	if flagsEndedByDashDash(fs, osArgs[1:]) {
+		// The flag package removed the "--" ending the flags.
+		procArgs, args.files = nil, procArgs
+	}
+	for i, spec := range procArgs {
+		if spec == "--" {
+			args.files = procArgs[i+1:]
+			break
+		}
+		if name, _ := registry.SplitSpec(spec); !registry.IsValidName(name) {
+			args.files = procArgs[i:]
+			break
+		}
+		runeProc, err := reg.Build(spec)
+		if err != nil {
+			return nil, err
+		}
+		args.runeProcs = append(args.runeProcs, runeProc)
+	}
+	if len(args.files) > 0 && !args.fileModes() {
+		return nil, errors.New("files require -check, -config, -d," +
+			" -editorconfig, -invisible, -r, -stats or -w")
+	}
+	if (args.write || args.recursive) && len(args.files) == 0 {
+		return nil, errors.New("-w and -r require files")
+	}
+	if args.editorConfig != nil && len(args.files) == 0 {
+		return nil, errors.New("-editorconfig requires files")
+	}
+	if args.config != nil && len(args.files) == 0 {
+		return nil, errors.New("-config requires files")
+	}
+	return args, nil
+}
+
+func write(runeCh <-chan rune, errCh <-chan error, ioW io.Writer) (err error) {
+	bufW := bufio.NewWriter(ioW)
+	defer func() {
+		if flushErr := bufW.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
+
+	for r := range runeCh {
+		if _, err = bufW.WriteRune(r); err != nil {
+			return
+		}
+	}
+
+	return <-errCh
+}
+
+// check reports the first position where processing changes
+// the input named name.
+func check(runeProc textproc.RuneProcessor, r io.Reader, name string,
+	w io.Writer) (changed bool, err error) {
+	pos, err := textproc.Check(r, runeProc)
+	if err != nil || pos == nil {
+		return false, err
+	}
+	_, err = fmt.Fprint(w, name, ":", pos, ": processing changes the input\n")
+	return true, err
+}
+
+// diffStdin writes the unified diff between r and its processed output.
+func diffStdin(args *cmdArgs, runeProc textproc.RuneProcessor, r io.Reader,
+	w io.Writer) (changed bool, err error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	output, err := processBytes(runeProc, content)
+	if err != nil || bytes.Equal(output, content) {
+		return
+	}
+	return true, writeDiff(args, "<stdin>", content, output, w)
+}
+
+// report writes err to w, prefixed by the program name.
+func report(w io.Writer, name string, err error) {
+	if name != "" {
+		fmt.Fprint(w, name, ": ")
+	}
+	fmt.Fprintln(w, "error:", err)
+}
+
+// Main runs the command with os.Args, stdin and stdout
+// using the processors of reg, and exits with its status.
+func Main(reg *registry.Registry) {
+	os.Exit(Run(reg, os.Args, os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Run runs the command with the arguments osArgs, including the program
+// name, using the processors of reg, and returns the exit status:
+// 0 on success, 1 on error, 2 for invalid flags
+// and ExitChanged if -check or -d find changes
+// or -invisible finds invisible characters.
+func Run(reg *registry.Registry, osArgs []string, stdin io.Reader,
+	stdout, stderr io.Writer) int {
+	var name string
+	if len(osArgs) > 0 {
+		name = osArgs[0]
+	}
+	failed := false
+	fail := func(err error) {
+		report(stderr, name, err)
+		failed = true
+	}
+
+	args, err := parseArgs(reg, osArgs, stderr)
+	switch err.(type) {
+	case nil:
+	case flagError:
+		return 2
+	default:
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fail(err)
+		return 1
+	}
+
+	runeProc := registry.Chain(args.runeProcs...)
+	changed := false
+	switch {
+	case len(args.files) > 0:
+		changed = processFiles(args, runeProc, stdout, fail)
+	case args.diff:
+		changed, err = diffStdin(args, runeProc, stdin, stdout)
+	case args.check:
+		changed, err = check(runeProc, stdin, "<stdin>", stdout)
+	case args.stats:
+		err = writeStats(args, runeProc, stdin, "<stdin>", stdout)
+	case args.invisible:
+		changed, err = reportInvisible(args, runeProc, stdin, "<stdin>",
+			stdout)
+	default:
+		runeCh, errCh := runeProc(textproc.ReadRunes(stdin))
+		err = write(runeCh, errCh, stdout)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	switch {
+	case failed:
+		return 1
+	case (args.check || args.diff || args.invisible) && changed:
+		return ExitChanged
+	}
+	return 0
+}
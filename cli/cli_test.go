@@ -0,0 +1,471 @@
+package cli
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var testRegistry = registry.Builtin()
+
+// mustBuild returns the processor of testRegistry specified by spec.
+func mustBuild(t *testing.T, spec string) textproc.RuneProcessor {
+	runeProc, err := testRegistry.Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runeProc
+}
+
+func TestParseArgsNoPrgName(t *testing.T) {
+	args, err := parseArgs(testRegistry, nil, io.Discard)
+	if args != nil || err != errNoProgramName {
+		t.Error("Want", nil, errNoProgramName, "got", args, err)
+	}
+}
+
+func TestParseArgsUnknownProcessor(t *testing.T) {
+	args, err := parseArgs(testRegistry, []string{"cmd", "lf", "myproc", "lf"},
+		io.Discard)
+	wantMsg := "unknown processor: myproc"
+	if err == nil || err.Error() != wantMsg {
+		t.Error("Want", wantMsg, "got", err)
+	}
+	if args != nil {
+		t.Error("Want", nil, "got", args)
+	}
+}
+
+func TestParseArgsProcs(t *testing.T) {
+	for _, tc := range []*struct {
+		osArgs        []string
+		processorsLen int
+	}{
+		{[]string{"cmd"}, 0},
+		{[]string{"cmd", "lf"}, 1},
+		{[]string{"cmd", "lf", "lf"}, 2},
+		{[]string{"cmd", "lf", "sortpi", "lf"}, 3},
+		{[]string{"cmd", "norm"}, 1},
+		{[]string{"cmd", "sortti", "sortli"}, 2},
+	} {
+		args, err := parseArgs(testRegistry, tc.osArgs, io.Discard)
+		if err != nil {
+			t.Fatal("Want", nil, "got", err)
+		}
+		if len(args.runeProcs) != tc.processorsLen {
+			t.Fatal("Want", tc.processorsLen,
+				"got", len(args.runeProcs))
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	for _, tc := range []*struct {
+		str string
+		err error
+	}{
+		{"", nil},
+		{"", textproc.ErrInvalidUTF8},
+		{"ø🚲🛥ô🐁", nil},
+		{"∀ 𝒸: 𝒶≥𝒷.", io.ErrUnexpectedEOF},
+	} {
+		runeCh := make(chan rune)
+		errCh := make(chan error)
+		go func() {
+			for _, r := range []rune(tc.str) {
+				runeCh <- r
+			}
+			close(runeCh)
+			errCh <- tc.err
+			close(errCh)
+		}()
+
+		builder := &strings.Builder{}
+
+		gotErr := write(runeCh, errCh, builder)
+		if gotErr != tc.err {
+			t.Fatal("Want", tc.err, "got", gotErr)
+		}
+
+		gotStr := builder.String()
+		if gotStr != tc.str {
+			t.Fatalf("Want %#v got %#v", tc.str, gotStr)
+		}
+	}
+}
+
+// checkParsedArgs checks the output of the processors parsed from osArgs.
+func checkParsedArgs(t *testing.T, osArgs []string, in, want string) {
+	args, err := parseArgs(testRegistry, osArgs, io.Discard)
+	if err != nil {
+		t.Fatal("Want", nil, "got", err)
+	}
+	runeCh, errCh := registry.Chain(args.runeProcs...)(
+		textproc.ReadRunes(strings.NewReader(in)))
+	internal.CheckRuneChannel(t, runeCh, want)
+	internal.CheckErrorChannel(t, errCh, nil)
+}
+
+func TestParseArgsSubst(t *testing.T) {
+	args, err := parseArgs(testRegistry, []string{"cmd", "subst"}, io.Discard)
+	wantMsg := "subst: missing parameter: s"
+	if err == nil || err.Error() != wantMsg {
+		t.Error("Want", wantMsg, "got", err)
+	}
+	if args != nil {
+		t.Error("Want", nil, "got", args)
+	}
+
+	args, err = parseArgs(testRegistry,
+		[]string{"cmd", "norm", "subst:s=s/a/b/",
+			"substp:s=s/a/b/,s=s/\\n//g"}, io.Discard)
+	if err != nil {
+		t.Fatal("Want", nil, "got", err)
+	}
+	if len(args.runeProcs) != 3 {
+		t.Fatal("Want", 3, "got", len(args.runeProcs))
+	}
+
+	runeCh, errCh := registry.Chain(args.runeProcs...)(
+		textproc.ReadRunes(strings.NewReader("a\r\na\n\naa")))
+	internal.CheckRuneChannel(t, runeCh, "bb\n\nbb\n")
+	internal.CheckErrorChannel(t, errCh, nil)
+}
+
+func TestParseArgsCut(t *testing.T) {
+	for _, tc := range []*struct {
+		osArgs   []string
+		in, want string
+	}{
+		{[]string{"cmd", "cut:fields=2\\,1"},
+			"a\tb\tc\n", "b\ta\n"},
+		{[]string{"cmd", "cut:fields=3-,delim=\\,"},
+			"a,b,c,d\n", "c,d\n"},
+		{[]string{"cmd", `cut:fields=1\,3,delim=" +",regexp,out-delim=;`},
+			"a  b c\n", "a;c\n"},
+		{[]string{"cmd", "cut:fields=-2,delim=[,regexp=false"},
+			"x[y[z\n", "x[y\n"},
+		{[]string{"cmd", "cutc:fields=2"}, "αβγ\n", "β\n"},
+		{[]string{"cmd", "cutd:fields=3\\,1,out-delim=|"},
+			"日本\n", "本|日\n"},
+	} {
+		checkParsedArgs(t, tc.osArgs, tc.in, tc.want)
+	}
+
+	for osArgs, wantMsg := range map[string]string{
+		"cut":           "cut: missing parameter: fields",
+		"cutc:fields=0": "cutc: " + textproc.ErrInvalidFieldList.Error(),
+		"cut:fields=1,regexp,delim=(": "cut: error parsing regexp: " +
+			"missing closing ): `(`",
+		"cut:fields":            "cut: fields: missing value",
+		"cut:fields=1,fields=2": "cut: duplicate parameter: fields",
+		"cut:width=1":           `cut: unknown parameter: "width"`,
+		"cut:fields=1,regexp=x": `cut: regexp: not a boolean: "x"`,
+		"lf:x":                  `lf: unknown parameter: "x"`,
+	} {
+		args, err := parseArgs(testRegistry, append([]string{"cmd"},
+			strings.Fields(osArgs)...), io.Discard)
+		if err == nil || err.Error() != wantMsg {
+			t.Error("Want", wantMsg, "got", err)
+		}
+		if args != nil {
+			t.Error("Want", nil, "got", args)
+		}
+	}
+}
+
+func TestParseArgsCSV(t *testing.T) {
+	for _, tc := range []*struct {
+		osArgs   []string
+		in, want string
+	}{
+		{[]string{"cmd", "sortcsv"}, "b,1\na,2\n", "a,2\nb,1\n"},
+		{[]string{"cmd", "sortcsv:column=2,header"},
+			"h,h\nb,1\na,2\n", "h,h\nb,1\na,2\n"},
+		{[]string{"cmd", "normcsv:comma=;,out-comma=\\,"},
+			"\"a\";b,c\n", "a,\"b,c\"\n"},
+		{[]string{"cmd", "normcsv:quote-all"}, "a,b\n",
+			"\"a\",\"b\"\n"},
+		{[]string{"cmd", "csv2tsv"}, "a,\"b\tc\"\n", "a\tb\\tc\n"},
+		{[]string{"cmd", "tsv2csv:out-comma=;"}, "a\tb;c\n",
+			"a;\"b;c\"\n"},
+	} {
+		checkParsedArgs(t, tc.osArgs, tc.in, tc.want)
+	}
+
+	for osArgs, wantMsg := range map[string]string{
+		"sortcsv:column=0": "sortcsv: column must be at least 1",
+		"sortcsv:column=x": `sortcsv: column: not an integer: "x"`,
+		"normcsv:comma=;;": `normcsv: comma: not a single character: ";;"`,
+	} {
+		args, err := parseArgs(testRegistry, []string{"cmd", osArgs},
+			io.Discard)
+		if err == nil || err.Error() != wantMsg {
+			t.Error("Want", wantMsg, "got", err)
+		}
+		if args != nil {
+			t.Error("Want", nil, "got", args)
+		}
+	}
+}
+
+func TestParseArgsKeepSorted(t *testing.T) {
+	for _, tc := range []*struct {
+		osArgs   []string
+		in, want string
+	}{
+		{[]string{"cmd", "keepsortli"},
+			"b\n# keep-sorted start\nd\nc\n# keep-sorted end\na\n",
+			"b\n# keep-sorted start\nc\nd\n# keep-sorted end\na\n"},
+		{[]string{"cmd", "keepsortpi:start=BEGIN,end=END"},
+			"BEGIN\nb\n\na\nEND\n", "BEGIN\na\n\nb\nEND\n"},
+	} {
+		checkParsedArgs(t, tc.osArgs, tc.in, tc.want)
+	}
+}
+
+func TestParseArgsSortLCI(t *testing.T) {
+	checkParsedArgs(t, []string{"cmd", "sortlci"},
+		"# h\n\n# b\nb\na\n", "# h\n\na\n# b\nb\n")
+	checkParsedArgs(t, []string{"cmd", "sortlci:comment=//"},
+		"c\n// b\nb\na\n", "a\n// b\nb\nc\n")
+
+	args, err := parseArgs(testRegistry, []string{"cmd", "sortlci:comment="},
+		io.Discard)
+	wantMsg := "sortlci: empty comment"
+	if err == nil || err.Error() != wantMsg {
+		t.Error("Want", wantMsg, "got", err)
+	}
+	if args != nil {
+		t.Error("Want", nil, "got", args)
+	}
+}
+
+func TestParseArgsSortINI(t *testing.T) {
+	checkParsedArgs(t, []string{"cmd", "sortini"},
+		"[b]\nz=1\ny=2\n[a]\n", "[b]\ny=2\nz=1\n[a]\n")
+	checkParsedArgs(t, []string{"cmd", "sortini:sections"},
+		"[b]\nz=1\ny=2\n[a]\n", "[a]\n[b]\ny=2\nz=1\n")
+}
+
+func TestParseArgsSortItemsI(t *testing.T) {
+	checkParsedArgs(t, []string{"cmd", "sortitemsi"},
+		"c,b, a\n", "a, b, c\n")
+	checkParsedArgs(t, []string{"cmd",
+		`sortitemsi:prefix="^Depends: ",delim=;,unique`},
+		"Depends: z;y;z\nz;y\n", "Depends: y; z\nz;y\n")
+
+	for osArgs, wantMsg := range map[string]string{
+		"sortitemsi:delim=": "sortitemsi: empty delim",
+		"sortitemsi:prefix=(": "sortitemsi: error parsing regexp: " +
+			"missing closing ): `(`",
+	} {
+		args, err := parseArgs(testRegistry, append([]string{"cmd"},
+			strings.Fields(osArgs)...), io.Discard)
+		if err == nil || err.Error() != wantMsg {
+			t.Error("Want", wantMsg, "got", err)
+		}
+		if args != nil {
+			t.Error("Want", nil, "got", args)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	args, err := parseArgs(testRegistry, []string{"cmd", "-check", "norm"},
+		io.Discard)
+	if err != nil {
+		t.Fatal("Want", nil, "got", err)
+	}
+	if !args.check {
+		t.Fatal("Want", true, "got", args.check)
+	}
+	runeProc := registry.Chain(args.runeProcs...)
+
+	for _, tc := range []*struct {
+		in, out string
+		changed bool
+		err     error
+	}{
+		{"", "", false, nil},
+		{"a\nb\n", "", false, nil},
+		{"a\nb \n", "in:2:2: processing changes the input\n", true, nil},
+		{"a\xff", "", false, textproc.ErrInvalidUTF8},
+	} {
+		builder := &strings.Builder{}
+		changed, err := check(runeProc, strings.NewReader(tc.in), "in",
+			builder)
+		if changed != tc.changed || err != tc.err {
+			t.Fatal("Want", tc.changed, tc.err, "got", changed, err)
+		}
+		if got := builder.String(); got != tc.out {
+			t.Fatalf("Want %#v got %#v", tc.out, got)
+		}
+	}
+}
+
+func TestDiffStdin(t *testing.T) {
+	args, err := parseArgs(testRegistry,
+		[]string{"cmd", "-d", "-context", "1", "norm"}, io.Discard)
+	if err != nil {
+		t.Fatal("Want", nil, "got", err)
+	}
+	if !args.diff || args.context != 1 {
+		t.Fatal("Unexpected", args)
+	}
+	runeProc := registry.Chain(args.runeProcs...)
+
+	for _, tc := range []*struct {
+		in, out string
+		changed bool
+		err     error
+	}{
+		{"", "", false, nil},
+		{"a\nb\n", "", false, nil},
+		{"a\nb \t\r\nc\nd\n", "--- <stdin>\t(original)\n" +
+			"+++ <stdin>\t(processed)\n" +
+			"@@ -1,3 +1,3 @@\n a\n-b·→␍\n+b\n c\n", true, nil},
+		{"a\xff", "", false, textproc.ErrInvalidUTF8},
+	} {
+		builder := &strings.Builder{}
+		changed, err := diffStdin(args, runeProc, strings.NewReader(tc.in),
+			builder)
+		if changed != tc.changed || err != tc.err {
+			t.Fatal("Want", tc.changed, tc.err, "got", changed, err)
+		}
+		if got := builder.String(); got != tc.out {
+			t.Fatalf("Want %#v got %#v", tc.out, got)
+		}
+	}
+}
+
+func TestMarkWhiteSpace(t *testing.T) {
+	for in, want := range map[string]string{
+		"":           "",
+		"a b":        "a b",
+		"a b \t":     "a b·→",
+		"a\rb\u00a0": "a␍b·",
+		" \r":        "·␍",
+	} {
+		if got := markWhiteSpace(in); got != want {
+			t.Errorf("%#v: want %#v got %#v", in, want, got)
+		}
+	}
+}
+
+func TestParseArgsFiles(t *testing.T) {
+	args, err := parseArgs(testRegistry, []string{"cmd", "-w", "-backup", "~",
+		"norm", "sortli", "a.txt", "norm"}, io.Discard)
+	if err != nil {
+		t.Fatal("Want", nil, "got", err)
+	}
+	if len(args.runeProcs) != 2 || !args.write || args.backup != "~" ||
+		strings.Join(args.files, " ") != "a.txt norm" {
+		t.Fatal("Unexpected", args)
+	}
+
+	for osArgs, want := range map[string]*struct {
+		procs int
+		files string
+	}{
+		"-check norm -- a b":       {1, "a b"},
+		"-check norm ./a b":        {1, "./a b"},
+		"-w -- trail norm":         {0, "trail norm"},
+		"-w trail -- norm":         {1, "norm"},
+		"-w -backup -- lf f.txt":   {1, "f.txt"},
+		"-w -backup=-- -- lf":      {0, "lf"},
+		"-stats norm lf -- a":      {2, "a"},
+		"-editorconfig -- lf":      {0, "lf"},
+		"-config -backup -- -- lf": {0, "lf"},
+	} {
+		args, err := parseArgs(testRegistry, append([]string{"cmd"},
+			strings.Fields(osArgs)...), io.Discard)
+		if err != nil {
+			t.Fatal(osArgs, "want", nil, "got", err)
+		}
+		if len(args.runeProcs) != want.procs ||
+			strings.Join(args.files, " ") != want.files {
+			t.Error(osArgs, "unexpected", args)
+		}
+	}
+
+	for osArgs, wantMsg := range map[string]string{
+		"-w norm":          "-w and -r require files",
+		"-r norm":          "-w and -r require files",
+		"-w -check norm f": "-check and -w are mutually exclusive",
+		"-d -check norm f": "-check and -d are mutually exclusive",
+		"-d -context -1 f": "-context must not be negative",
+		"-editorconfig":    "-editorconfig requires files",
+		"-config norm":     "-config requires files",
+		"norm f.txt": "files require -check, -config, -d, -editorconfig," +
+			" -invisible, -r, -stats or -w",
+		"norm -- a": "files require -check, -config, -d, -editorconfig," +
+			" -invisible, -r, -stats or -w",
+		"-w nrom f.txt":    "unknown processor: nrom",
+		"-w norm a":        "unknown processor: a",
+		"-w norm --":       "-w and -r require files",
+		"-stats -d norm f": "-stats and -check, -d or -w are mutually exclusive",
+		"-json norm":       "-json requires -stats",
+		"-invisible -stats f": "-invisible and -check, -d, -stats or -w" +
+			" are mutually exclusive",
+		"-allow U+200B norm": "-allow requires -invisible",
+	} {
+		args, err := parseArgs(testRegistry, append([]string{"cmd"},
+			strings.Fields(osArgs)...), io.Discard)
+		if err == nil || err.Error() != wantMsg {
+			t.Error("Want", wantMsg, "got", err)
+		}
+		if args != nil {
+			t.Error("Want", nil, "got", args)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	reg := registry.Builtin()
+	reg.MustRegister("upper", &registry.Processor{Doc: "Upper case",
+		RuneProcessor: registry.Chain(textproc.SubstituteLFLines(
+			&textproc.Substitution{Regexp: regexp.MustCompile("a"),
+				Replacement: "A", Global: true}))})
+
+	for _, tc := range []*struct {
+		osArgs           string
+		stdin            string
+		status           int
+		stdout, inStderr string
+	}{
+		{"cmd upper norm", "a b \na", 0, "A b\nA\n", ""},
+		{"cmd", "a\xff", 1, "a", "cmd: error: invalid UTF-8"},
+		{"cmd nope", "", 1, "", "cmd: error: unknown processor: nope"},
+		{"cmd -nope", "", 2, "", "flag provided but not defined: -nope"},
+		{"cmd -help", "", 0, "", "\tupper\tUpper case\n"},
+		{"cmd -check upper", "b\na\n", ExitChanged,
+			"<stdin>:2:1: processing changes the input\n", ""},
+		{"cmd -d upper", "b\n", 0, "", ""},
+		{"cmd -stats -json lf", "a\r\n", 0, `{"name":"<stdin>",` +
+			`"bytes":2,"runes":2,"lines":1,"paragraphs":1,"words":1,` +
+			`"longest_line":1,"lf":1,"crlf":0,"cr":0,` +
+			`"trailing_white_space_lines":0,"tab_indented_lines":0,` +
+			`"space_indented_lines":0,"bom":false,"final_newline":true}` +
+			"\n", ""},
+		{"cmd -invisible -allow U+00AD", "a\u00ad\u200b\nb\u202e", ExitChanged,
+			"<stdin>:1:3: U+200B ZERO WIDTH SPACE\n" +
+				"<stdin>:2:2: U+202E RIGHT-TO-LEFT OVERRIDE\n", ""},
+		{"cmd -invisible stripinv", "a\u200b\n", 0, "", ""},
+		{"cmd -invisible -allow x", "", 2, "", `invalid code point: "x"`},
+	} {
+		stdout, stderr := &strings.Builder{}, &strings.Builder{}
+		status := Run(reg, strings.Fields(tc.osArgs),
+			strings.NewReader(tc.stdin), stdout, stderr)
+		if status != tc.status || stdout.String() != tc.stdout ||
+			!strings.Contains(stderr.String(), tc.inStderr) {
+			t.Errorf("%#v: want %v %#v %#v got %v %#v %#v", tc.osArgs,
+				tc.status, tc.stdout, tc.inStderr,
+				status, stdout.String(), stderr.String())
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/registry"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// configFileName is the name of the project configuration files.
+const configFileName = ".textproc"
+
+var aliasNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// A configRule maps the files matching a glob to a processor chain.
+type configRule struct {
+	re       *regexp.Regexp
+	runeProc textproc.RuneProcessor
+}
+
+// configChain returns the chain of the processors of reg specified by
+// value, a pipeline specification (see registry.ParsePipeline).
+// Errors are reported as registry.SpecError, positioned within value.
+func configChain(reg *registry.Registry, value string) (
+	textproc.RuneProcessor, error) {
+	pipeline, err := registry.ParsePipeline(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipeline.Steps) == 0 {
+		return nil, errors.New("no processors")
+	}
+	return reg.BuildPipeline(pipeline)
+}
+
+// parseConfig parses the content of a .textproc file.
+//
+// Each line is empty, a "#" comment, a rule "glob: processors"
+// or an alias "name = processors".
+// Processors are a pipeline specification (see registry.ParsePipeline)
+// of processors of reg and aliases defined on earlier lines.
+// Globs match the path relative to the directory of the file,
+// like EditorConfig globs.
+// Errors are reported as textproc.LineError,
+// with the column of the errors in processors.
+func parseConfig(reg *registry.Registry, content []byte) (
+	[]*configRule, error) {
+	var rules []*configRule
+	// Aliases are registered in a clone of reg.
+	aliasReg, aliases := reg.Clone(), map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 1
+	for ; scanner.Scan(); lineNo++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// indent is the length of the white space before line.
+		indent := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+
+		colon, eq := strings.Index(line, ":"), strings.Index(line, "=")
+		// value is the offset in text of the processors.
+		var value int
+		var err error
+		switch {
+		case eq >= 0 && (colon < 0 || eq < colon):
+			value = indent + eq + 1
+			err = parseAlias(aliasReg, line[:eq], line[eq+1:], aliases)
+		case colon >= 0:
+			value = indent + colon + 1
+			var rule *configRule
+			if rule, err = parseRule(aliasReg, line[:colon],
+				line[colon+1:]); err == nil {
+				rules = append(rules, rule)
+			}
+		default:
+			err = errors.New(
+				`want "glob: processors" or "name = processors"`)
+		}
+		var specErr registry.SpecError
+		if errors.As(err, &specErr) {
+			err = fmt.Errorf("column %d: %w", utf8.RuneCountInString(
+				text[:value])+specErr.Position.Column, specErr.Err)
+		}
+		if err != nil {
+			return nil, textproc.LineError{Line: lineNo, Err: err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, textproc.LineError{Line: lineNo, Err: err}
+	}
+	return rules, nil
+}
+
+// parseAlias registers in reg the alias name for the processors in value
+// and records it in aliases.
+func parseAlias(reg *registry.Registry, name, value string,
+	aliases map[string]bool) error {
+	name = strings.TrimSpace(name)
+	_, isProcessor := reg.Lookup(name)
+	switch {
+	case !aliasNameRegexp.MatchString(name):
+		return fmt.Errorf("invalid alias name: %#v", name)
+	case aliases[name]:
+		return errors.New("duplicate alias: " + name)
+	case isProcessor:
+		return errors.New("alias shadows a processor: " + name)
+	}
+
+	runeProc, err := configChain(reg, value)
+	if err != nil {
+		return err
+	}
+	aliases[name] = true
+	return reg.Register(name, &registry.Processor{
+		Doc: "Alias: " + strings.TrimSpace(value), RuneProcessor: runeProc})
+}
+
+// parseRule returns the rule for the files matching glob.
+func parseRule(reg *registry.Registry, glob, value string) (
+	*configRule, error) {
+	glob = strings.TrimSpace(glob)
+	if glob == "" {
+		return nil, errors.New("empty glob")
+	}
+	re, err := regexp.Compile("^" + braceGlobPattern(pathGlob(glob)) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %#v: %w", glob, err)
+	}
+	runeProc, err := configChain(reg, value)
+	if err != nil {
+		return nil, err
+	}
+	return &configRule{re, runeProc}, nil
+}
+
+// A configFileError is an error of a .textproc file.
+type configFileError struct {
+	name string
+	err  error
+}
+
+func (e *configFileError) Error() string {
+	return e.name + ": " + e.err.Error()
+}
+
+// Unwrap returns e.err.
+func (e *configFileError) Unwrap() error {
+	return e.err
+}
+
+// projectConfig finds the processors of files in .textproc files.
+type projectConfig struct {
+	// reg builds the processors.
+	reg *registry.Registry
+	// rules maps directories to the rules of their .textproc file,
+	// or to nil if they have none.
+	rules map[string][]*configRule
+	// errs maps directories to the error of their .textproc file.
+	errs map[string]error
+}
+
+// load returns the rules of the .textproc file of dir.
+// Errors are *configFileError values;
+// each call for dir returns the same one.
+func (c *projectConfig) load(dir string) ([]*configRule, error) {
+	if c.rules == nil {
+		c.rules, c.errs = map[string][]*configRule{}, map[string]error{}
+	}
+	if rules, ok := c.rules[dir]; ok {
+		return rules, c.errs[dir]
+	}
+
+	name := filepath.Join(dir, configFileName)
+	content, err := os.ReadFile(name)
+	var rules []*configRule
+	switch {
+	case err == nil:
+		rules, err = parseConfig(c.reg, content)
+	case os.IsNotExist(err):
+		err = nil
+	}
+	if err != nil {
+		err = &configFileError{name, err}
+	}
+	c.rules[dir], c.errs[dir] = rules, err
+	return rules, err
+}
+
+// processor returns the processor chain of the file at path,
+// or nil if no rule matches it.
+// The .textproc files are searched from the directory of the file upwards;
+// the last matching rule of the first file with a matching rule decides.
+func (c *projectConfig) processor(path string) (
+	textproc.RuneProcessor, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		rules, err := c.load(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(rules) > 0 {
+			rel, err := filepath.Rel(dir, abs)
+			if err != nil {
+				return nil, err
+			}
+			rel = filepath.ToSlash(rel)
+			for i := len(rules) - 1; i >= 0; i-- {
+				if rules[i].re.MatchString(rel) {
+					return rules[i].runeProc, nil
+				}
+			}
+		}
+		if dir == filepath.Dir(dir) {
+			return nil, nil
+		}
+	}
+}
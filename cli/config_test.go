@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	for content, want := range map[string]string{
+		"*.txt norm":                           "line 1: want \"glob: processors\" or \"name = processors\"",
+		"# c\n\n*.txt: norm\n*.md: nope":       "line 4: column 7: unknown processor: nope",
+		"*.txt:":                               "line 1: no processors",
+		" : norm":                              "line 1: empty glob",
+		"tidy = lf\ntidy = lf":                 "line 2: duplicate alias: tidy",
+		"norm = lf":                            "line 1: alias shadows a processor: norm",
+		"My-Alias = lf":                        "line 1: invalid alias name: \"My-Alias\"",
+		"*.csv: tidy\ntidy = lf":               "line 1: column 8: unknown processor: tidy",
+		"*.txt: norm\n*.tsv: cut":              "line 2: column 8: cut: missing parameter: fields",
+		"*.txt: indent:prefix=\"x":             "line 1: column 22: unterminated quoted value",
+		"*.txt: tidy:x\ntidy = lf":             "line 1: column 8: unknown processor: tidy",
+		"  x = lf  trail:y":                    "line 1: column 17: trail: unknown parameter: \"y\"",
+		"*.txt: subst:s=\"s/→/-/\" sortli:x=1": "line 1: column 32: sortli: unknown parameter: \"x\"",
+		"*.txt: sortli\n[z-a]: norm":           "line 2: invalid glob \"[z-a]\": error parsing regexp: invalid character class range: `z-a`",
+	} {
+		rules, err := parseConfig(testRegistry, []byte(content))
+		if err == nil || err.Error() != want {
+			t.Errorf("%#v: want %#v got %v", content, want, err)
+		}
+		var lineErr textproc.LineError
+		if !errors.As(err, &lineErr) {
+			t.Errorf("%#v: want LineError got %#v", content, err)
+		}
+		if rules != nil {
+			t.Errorf("%#v: want nil got %v", content, rules)
+		}
+	}
+
+	long := "*.txt: norm\n#" + strings.Repeat("x", bufio.MaxScanTokenSize) +
+		"\n*.md: sortli\n"
+	wantErr := textproc.LineError{Line: 2, Err: bufio.ErrTooLong}
+	if rules, err := parseConfig(testRegistry, []byte(long)); err !=
+		wantErr || rules != nil {
+		t.Error("Want", wantErr, "got", err, rules)
+	}
+
+	rules, err := parseConfig(testRegistry,
+		[]byte("# sorted lists\ntidy = norm sortli\n"+
+			"*.{txt,lst}: tidy\nCODEOWNERS: norm sortitemsi:delim=;,unique\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || !rules[0].re.MatchString("a/b.lst") ||
+		!rules[1].re.MatchString("CODEOWNERS") {
+		t.Fatal("Unexpected", rules)
+	}
+	output, err := processBytes(rules[0].runeProc, []byte("b \na"))
+	if string(output) != "a\nb\n" || err != nil {
+		t.Fatalf("Want %#v got %#v %v", "a\nb\n", string(output), err)
+	}
+}
+
+func TestProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".textproc": "tidy = norm sortli\n*.txt: norm\n" +
+			"*.lst: tidy\n/docs/*.txt: trail\n",
+		"a.txt":          "b \na\n\n",
+		"docs/b.txt":     "b \na\n\n",
+		"x/c.lst":        "b\na\n",
+		"x/d.go":         "b \n",
+		"bad/.textproc":  "*.txt: norm\nnope\n",
+		"bad/e.txt":      "e\n",
+		"sub/.textproc":  "*.md: sortli\n",
+		"sub/f.lst":      "y\nx\n",
+		"sub/g.md":       "y\nx\n",
+		"sub/.gitignore": "*.tmp\n",
+	})
+
+	args, err := parseArgs(testRegistry, []string{"cmd", "-config", "-r", dir},
+		io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	var errs []string
+	processFiles(args, registry.Chain(args.runeProcs...), builder,
+		func(err error) {
+			errs = append(errs, err.Error())
+		})
+
+	configErr := filepath.Join(dir, "bad", ".textproc") +
+		`: line 2: want "glob: processors" or "name = processors"`
+	if len(errs) != 1 || errs[0] != configErr {
+		t.Fatal("Want", []string{configErr}, "got", errs)
+	}
+	// The walk order is lexical: .textproc, a.txt, bad, docs, sub, x.
+	want := "tidy = norm sortli\n*.txt: norm\n*.lst: tidy\n" +
+		"/docs/*.txt: trail\n" +
+		"b\na\n" +
+		"b\na\n\n" +
+		"*.tmp\n" +
+		"*.md: sortli\n" +
+		"x\ny\n" +
+		"x\ny\n" +
+		"a\nb\n" +
+		"b \n"
+	if got := builder.String(); got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+}
+
+func TestSpecEntryPoints(t *testing.T) {
+	const spec = `indent:prefix="> " subst:s="s/,/;/"`
+	pipeline, err := registry.ParsePipeline(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pipeline.String(); got != spec {
+		t.Fatalf("Want %#v got %#v", spec, got)
+	}
+
+	compiled, err := testRegistry.Compile(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := parseArgs(testRegistry, append([]string{"cmd"},
+		pipeline.Steps[0].String(), pipeline.Steps[1].String()), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := parseConfig(testRegistry, []byte("quote = "+spec+
+		"\n*.txt: quote\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, runeProc := range map[string]textproc.RuneProcessor{
+		"Compile":   compiled,
+		"parseArgs": registry.Chain(args.runeProcs...),
+		"config":    rules[0].runeProc,
+	} {
+		output, err := processBytes(runeProc, []byte("a,b\n\nc\n"))
+		if want := "> a;b\n\n> c\n"; string(output) != want || err != nil {
+			t.Errorf("%s: want %#v got %#v %v", name, want, string(output), err)
+		}
+	}
+}
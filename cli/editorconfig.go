@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/registry"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxBraceRange is the largest numeric brace range
+// matched exactly, larger ranges match any integer.
+const maxBraceRange = 1000
+
+// matchingBrace returns the index of the "}" closing the "{" at glob[open],
+// or -1 if it is not closed.
+func matchingBrace(glob string, open int) int {
+	depth := 0
+	for i := open; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitBraceAlternatives splits the content of a brace group
+// at the commas outside nested groups.
+func splitBraceAlternatives(inner string) []string {
+	var alts []string
+	depth, start := 0, 0
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(alts, inner[start:])
+}
+
+var braceRangeRegexp = regexp.MustCompile(`^([+-]?[0-9]+)\.\.([+-]?[0-9]+)$`)
+
+// braceGroupPattern converts the content of a brace group
+// to a regular expression, or returns false if the group
+// is literal text: it has a single alternative which is not a range.
+func braceGroupPattern(inner string) (string, bool) {
+	alts := splitBraceAlternatives(inner)
+	if len(alts) > 1 {
+		for i := range alts {
+			alts[i] = braceGlobPattern(alts[i])
+		}
+		return "(?:" + strings.Join(alts, "|") + ")", true
+	}
+
+	m := braceRangeRegexp.FindStringSubmatch(inner)
+	if m == nil {
+		return "", false
+	}
+	lo, errLo := strconv.Atoi(m[1])
+	hi, errHi := strconv.Atoi(m[2])
+	if errLo != nil || errHi != nil || hi-lo > maxBraceRange ||
+		lo-hi > maxBraceRange {
+		return "[+-]?[0-9]+", true
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	var nums []string
+	for n := lo; n <= hi; n++ {
+		nums = append(nums, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(nums, "|") + ")", true
+}
+
+// braceGlobPattern converts a glob to an unanchored regular expression
+// like globPattern, and also supports the EditorConfig brace groups:
+// "{s1,s2}" matches any of the comma-separated globs
+// and "{n1..n2}" matches any integer between n1 and n2.
+func braceGlobPattern(glob string) string {
+	var re strings.Builder
+	start := 0
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+		case '{':
+			end := matchingBrace(glob, i)
+			if end < 0 {
+				continue
+			}
+			pattern, ok := braceGroupPattern(glob[i+1 : end])
+			if !ok {
+				continue
+			}
+			re.WriteString(globPattern(glob[start:i]))
+			re.WriteString(pattern)
+			start, i = end+1, end
+		}
+	}
+	re.WriteString(globPattern(glob[start:]))
+	return re.String()
+}
+
+// An editorConfigSection is a glob with its properties.
+type editorConfigSection struct {
+	re    *regexp.Regexp
+	props map[string]string
+}
+
+// An editorConfigFile is a parsed .editorconfig file.
+type editorConfigFile struct {
+	root     bool
+	sections []*editorConfigSection
+}
+
+// parseEditorConfig parses the content of an .editorconfig file.
+// Keys and values are lowercased.
+// Invalid lines and sections with invalid globs are skipped,
+// like EditorConfig plugins do.
+func parseEditorConfig(content []byte) (*editorConfigFile, error) {
+	file := &editorConfigFile{}
+	var section *editorConfigSection
+	preamble := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			preamble = false
+			glob := pathGlob(line[1 : len(line)-1])
+			section = &editorConfigSection{props: map[string]string{}}
+			re, err := regexp.Compile("^" + braceGlobPattern(glob) + "$")
+			if err == nil {
+				section.re = re
+				file.sections = append(file.sections, section)
+			}
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:eq]))
+		value := strings.ToLower(strings.TrimSpace(line[eq+1:]))
+		switch {
+		case preamble && key == "root":
+			file.root = value == "true"
+		case section != nil:
+			section.props[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// editorConfig resolves the .editorconfig properties of files.
+type editorConfig struct {
+	// reg builds the processors.
+	reg *registry.Registry
+	// files maps directories to their parsed .editorconfig file,
+	// or to nil if they have none.
+	files map[string]*editorConfigFile
+}
+
+// load returns the parsed .editorconfig file of dir, or nil if it has none.
+func (c *editorConfig) load(dir string) (*editorConfigFile, error) {
+	if c.files == nil {
+		c.files = map[string]*editorConfigFile{}
+	}
+	if file, ok := c.files[dir]; ok {
+		return file, nil
+	}
+
+	var file *editorConfigFile
+	name := filepath.Join(dir, ".editorconfig")
+	content, err := os.ReadFile(name)
+	switch {
+	case err == nil:
+		if file, err = parseEditorConfig(content); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	c.files[dir] = file
+	return file, nil
+}
+
+// properties returns the properties of the file at path.
+// The .editorconfig files are read from the directory of the file
+// up to the first one declaring root = true;
+// sections of deeper files, and later sections of the same file,
+// override earlier ones. Properties set to "unset" are removed.
+func (c *editorConfig) properties(path string) (map[string]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	var files []*editorConfigFile
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		file, err := c.load(dir)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			dirs, files = append(dirs, dir), append(files, file)
+			if file.root {
+				break
+			}
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	props := map[string]string{}
+	for i := len(files) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], abs)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, section := range files[i].sections {
+			if section.re.MatchString(rel) {
+				for k, v := range section.props {
+					props[k] = v
+				}
+			}
+		}
+	}
+	for k, v := range props {
+		if v == "unset" {
+			delete(props, k)
+		}
+	}
+	return props, nil
+}
+
+// positiveInt returns the value of a positive integer property.
+func positiveInt(props map[string]string, key string) (int, bool) {
+	n, err := strconv.Atoi(props[key])
+	return n, err == nil && n > 0
+}
+
+// editorConfigSpecs returns the built-in processors, with parameters,
+// applying the EditorConfig properties.
+// It reports if end_of_line is not set and the processors, which expect
+// "\n" line terminators, must run with those of the file kept.
+// Unknown properties and values are ignored.
+func editorConfigSpecs(props map[string]string) (specs []string,
+	keepEOL bool, err error) {
+	charset := props["charset"]
+	switch charset {
+	case "", "utf-8", "utf-8-bom":
+	default:
+		return nil, false, fmt.Errorf("unsupported charset: %s", charset)
+	}
+	if charset == "utf-8" {
+		specs = append(specs, "nobom")
+	}
+
+	eol := props["end_of_line"]
+	switch eol {
+	case "lf", "crlf", "cr":
+		specs = append(specs, "lf")
+	default:
+		eol = ""
+	}
+	if props["trim_trailing_whitespace"] == "true" {
+		specs = append(specs, "trail")
+	}
+	if props["insert_final_newline"] == "true" {
+		specs = append(specs, "nelf")
+	}
+
+	tabWidth := 8
+	if n, ok := positiveInt(props, "tab_width"); ok {
+		tabWidth = n
+	} else if n, ok := positiveInt(props, "indent_size"); ok {
+		tabWidth = n
+	}
+	switch props["indent_style"] {
+	case "space":
+		specs = append(specs, fmt.Sprint("spaceindent:width=", tabWidth))
+	case "tab":
+		specs = append(specs, fmt.Sprint("tabindent:width=", tabWidth))
+	}
+
+	// All but the charset processors expect "\n" line terminators.
+	n := len(specs)
+	if charset == "utf-8" {
+		n--
+	}
+	keepEOL = eol == "" && n > 0
+	if eol == "crlf" || eol == "cr" {
+		specs = append(specs, eol)
+	}
+	if charset == "utf-8-bom" {
+		specs = append(specs, "bom")
+	}
+	return
+}
+
+// processors returns the processors of c.reg applying
+// the EditorConfig properties of the file at path.
+func (c *editorConfig) processors(path string) (
+	[]textproc.RuneProcessor, error) {
+	props, err := c.properties(path)
+	if err != nil {
+		return nil, err
+	}
+	specs, keepEOL, err := editorConfigSpecs(props)
+	if err != nil {
+		return nil, err
+	}
+
+	var runeProcs []textproc.RuneProcessor
+	for _, spec := range specs {
+		runeProc, err := c.reg.Build(spec)
+		if err != nil {
+			return nil, err
+		}
+		runeProcs = append(runeProcs, runeProc)
+	}
+	if keepEOL {
+		runeProcs = []textproc.RuneProcessor{
+			textproc.KeepLineTerminators(registry.Chain(runeProcs...))}
+	}
+	return runeProcs, nil
+}
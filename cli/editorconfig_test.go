@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBraceGlobPattern(t *testing.T) {
+	for glob, want := range map[string]*struct {
+		match, noMatch []string
+	}{
+		"*.{js,ts}":  {[]string{"a.js", "b.ts"}, []string{"a.go", "a.{js,ts}"}},
+		"{a,{b,c}d}": {[]string{"a", "bd", "cd"}, []string{"b", "ad"}},
+		"{a}":        {[]string{"{a}"}, []string{"a"}},
+		"a{b":        {[]string{"a{b"}, []string{"ab"}},
+		`\{a,b}`:     {[]string{"{a,b}"}, []string{"a", "{a"}},
+		"f{1..12}":   {[]string{"f1", "f9", "f12"}, []string{"f0", "f13"}},
+		"f{3..-1}":   {[]string{"f-1", "f0", "f3"}, []string{"f4", "f-2"}},
+		"{lib,src}/**/*.go": {[]string{"lib/a.go", "src/x/y/b.go"},
+			[]string{"test/a.go", "lib/a.js"}},
+	} {
+		re := regexp.MustCompile("^" + braceGlobPattern(glob) + "$")
+		for _, s := range want.match {
+			if !re.MatchString(s) {
+				t.Errorf("%#v: want match for %#v", glob, s)
+			}
+		}
+		for _, s := range want.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("%#v: want no match for %#v", glob, s)
+			}
+		}
+	}
+}
+
+func TestEditorConfigSpecs(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".editorconfig": "root = true\n[*]\nend_of_line = LF\n" +
+			"insert_final_newline = true\n" +
+			"[*.{go,mk}]\nindent_style = tab\n[*.bat]\nend_of_line = crlf\n",
+		"sub/.editorconfig": "; no root\n[*.md]\ntrim_trailing_whitespace" +
+			" = true\nindent_style = space\nindent_size = 2\n" +
+			"[/gen/**]\ninsert_final_newline = unset\ncharset = latin1\n" +
+			"[*.txt]\ncharset = utf-8-bom\nend_of_line = unset\n" +
+			"invalid line\n",
+		"sub/deep/.editorconfig": "root = true\n[*]\ntab_width = 4\n" +
+			"indent_style = tab\n",
+	})
+
+	c := &editorConfig{reg: testRegistry}
+	for name, want := range map[string]*struct {
+		specs   string
+		keepEOL bool
+		err     string
+	}{
+		"a.go":          {"lf nelf tabindent:width=8", false, ""},
+		"a.bat":         {"lf nelf crlf", false, ""},
+		"sub/a.md":      {"lf trail nelf spaceindent:width=2", false, ""},
+		"sub/x/y/b.md":  {"lf trail nelf spaceindent:width=2", false, ""},
+		"sub/gen/a.md":  {"", false, "unsupported charset: latin1"},
+		"sub/gen/a.txt": {"bom", false, ""},
+		"sub/a.txt":     {"nelf bom", true, ""},
+		"sub/deep/a.md": {"tabindent:width=4", true, ""},
+	} {
+		props, err := c.properties(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		specs, keepEOL, err := editorConfigSpecs(props)
+		if err != nil {
+			if err.Error() != want.err {
+				t.Errorf("%s: want error %#v got %v", name, want.err, err)
+			}
+			continue
+		}
+		if got := strings.Join(specs, " "); got != want.specs ||
+			keepEOL != want.keepEOL || want.err != "" {
+			t.Errorf("%s: want %v got %#v", name, want, got)
+		}
+	}
+
+	writeTree(t, dir, map[string]string{"long/.editorconfig": "[*]\n;" +
+		strings.Repeat("x", bufio.MaxScanTokenSize) + "\n"})
+	_, err := c.properties(filepath.Join(dir, "long", "a.txt"))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Error("Want", bufio.ErrTooLong, "got", err)
+	}
+}
+
+func TestProcessFilesEditorConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".editorconfig": "root = true\n[*.txt]\nend_of_line = crlf\n" +
+			"trim_trailing_whitespace = true\nindent_style = space\n" +
+			"tab_width = 4\n[*.md]\ntrim_trailing_whitespace = true\n",
+		"a.txt":   "a \n\tb\r\n\t\tc",
+		"b.md":    "b \r\na\r\n",
+		"c.md":    "a \r\nb \n",
+		"b.bin":   "x \n",
+		"x/c.txt": "sortme\nb\n",
+	})
+
+	args, err := parseArgs(testRegistry,
+		[]string{"cmd", "-editorconfig", "-w", "sortli",
+			filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.md"),
+			filepath.Join(dir, "c.md"),
+			filepath.Join(dir, "b.bin"), filepath.Join(dir, "x")}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.editorConfig == nil {
+		t.Fatal("Want editorConfig got nil")
+	}
+	var errs []string
+	changed := processFiles(args, registry.Chain(args.runeProcs...),
+		&strings.Builder{}, func(err error) {
+			errs = append(errs, err.Error())
+		})
+	if !changed {
+		t.Fatal("Want", true, "got", changed)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "x") {
+		t.Fatal("Want 1 error about the directory got", errs)
+	}
+	checkFileContent(t, filepath.Join(dir, "a.txt"),
+		"        c\r\n    b\r\na\r\n")
+	checkFileContent(t, filepath.Join(dir, "b.md"), "a\r\nb\r\n")
+	checkFileContent(t, filepath.Join(dir, "c.md"), "a\r\nb\n")
+	checkFileContent(t, filepath.Join(dir, "b.bin"), "x \n")
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/diff"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode"
+)
+
+// processBytes returns the output of runeProc for content.
+func processBytes(runeProc textproc.RuneProcessor, content []byte) (
+	[]byte, error) {
+	buf := &bytes.Buffer{}
+	runeCh, errCh := runeProc(textproc.ReadRunes(bytes.NewReader(content)))
+	if err := write(runeCh, errCh, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic replaces the file at path with content
+// by writing a temporary file in the same directory and renaming it.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) (
+	err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path),
+		"."+filepath.Base(path)+".*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// rewriteFile processes content, the content of the file at path,
+// with runeProc and, if the content changes, replaces the file atomically
+// keeping its permissions.
+// If backupSuffix is not empty, the original content is first saved
+// to a file with backupSuffix appended to the name.
+func rewriteFile(runeProc textproc.RuneProcessor, path string,
+	content []byte, backupSuffix string) (changed bool, err error) {
+	output, err := processBytes(runeProc, content)
+	if err != nil || bytes.Equal(output, content) {
+		return
+	}
+	return true, replaceFile(path, content, output, backupSuffix)
+}
+
+// replaceFile replaces content, the content of the file at path,
+// with output, like rewriteFile.
+func replaceFile(path string, content, output []byte,
+	backupSuffix string) (err error) {
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if backupSuffix != "" {
+		err = writeFileAtomic(path+backupSuffix, content, info.Mode().Perm())
+		if err != nil {
+			return
+		}
+	}
+	return writeFileAtomic(path, output, info.Mode().Perm())
+}
+
+// markWhiteSpace makes trailing white space and CR characters
+// in a diff line visible.
+func markWhiteSpace(line string) string {
+	runes := []rune(line)
+	end := len(runes)
+	for end > 0 && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+	for i, r := range runes {
+		switch {
+		case r == '\r':
+			runes[i] = '␍'
+		case i < end:
+		case r == '\t':
+			runes[i] = '→'
+		default:
+			runes[i] = '·'
+		}
+	}
+	return string(runes)
+}
+
+// writeDiff writes the unified diff between content, named name,
+// and output.
+func writeDiff(args *cmdArgs, name string, content, output []byte,
+	w io.Writer) error {
+	u := &diff.Unified{Context: args.context, Mark: markWhiteSpace}
+	return u.Write(w, name+"\t(original)", name+"\t(processed)",
+		diff.SplitLines(string(content)), diff.SplitLines(string(output)))
+}
+
+// diffFile writes the unified diff between content, the content
+// of the file at path, and its processed output, and with -w
+// replaces the file if the content changes.
+func diffFile(args *cmdArgs, runeProc textproc.RuneProcessor, path string,
+	content []byte, w io.Writer) (changed bool, err error) {
+	output, err := processBytes(runeProc, content)
+	if err != nil || bytes.Equal(output, content) {
+		return
+	}
+	if err = writeDiff(args, path, content, output, w); err != nil {
+		return
+	}
+	if args.write {
+		err = replaceFile(path, content, output, args.backup)
+	}
+	return true, err
+}
+
+// processFile applies the action selected by args to content,
+// the content of the file at path: check with -check,
+// diff with -d, rewrite with -w, write statistics with -stats,
+// report invisible characters with -invisible,
+// otherwise write the output to w.
+func processFile(args *cmdArgs, runeProc textproc.RuneProcessor,
+	path string, content []byte, w io.Writer) (changed bool, err error) {
+	switch {
+	case args.check:
+		return check(runeProc, bytes.NewReader(content), path, w)
+	case args.diff:
+		return diffFile(args, runeProc, path, content, w)
+	case args.write:
+		return rewriteFile(runeProc, path, content, args.backup)
+	case args.stats:
+		return false, writeStats(args, runeProc, bytes.NewReader(content),
+			path, w)
+	case args.invisible:
+		return reportInvisible(args, runeProc, bytes.NewReader(content),
+			path, w)
+	default:
+		runeCh, errCh := runeProc(
+			textproc.ReadRunes(bytes.NewReader(content)))
+		return false, write(runeCh, errCh, w)
+	}
+}
+
+// fileProcessor returns runeProc followed by the processors
+// of the file at path from its .textproc files with -config
+// and from its .editorconfig files with -editorconfig.
+func fileProcessor(args *cmdArgs, runeProc textproc.RuneProcessor,
+	path string) (textproc.RuneProcessor, error) {
+	runeProcs := []textproc.RuneProcessor{runeProc}
+	if args.config != nil {
+		configProc, err := args.config.processor(path)
+		if err != nil {
+			return nil, err
+		}
+		if configProc != nil {
+			runeProcs = append(runeProcs, configProc)
+		}
+	}
+	if args.editorConfig != nil {
+		editorProcs, err := args.editorConfig.processors(path)
+		if err != nil {
+			return nil, err
+		}
+		runeProcs = append(runeProcs, editorProcs...)
+	}
+	return registry.Chain(runeProcs...), nil
+}
+
+// processFiles applies the action selected by args to the files,
+// walking directories with -r, using the processors of fileProcessor.
+// It reports each error, prefixed by the file name, and continues.
+// It reports the error of a .textproc file once,
+// skipping the files which it applies to.
+func processFiles(args *cmdArgs, runeProc textproc.RuneProcessor,
+	w io.Writer, report func(error)) (anyChanged bool) {
+	reported := map[*configFileError]bool{}
+	process := func(path string, content []byte) {
+		fileProc, err := fileProcessor(args, runeProc, path)
+		var configErr *configFileError
+		switch {
+		case errors.As(err, &configErr):
+			if !reported[configErr] {
+				reported[configErr] = true
+				report(configErr)
+			}
+			return
+		case err != nil:
+			report(fmt.Errorf("%s: %w", path, err))
+			return
+		}
+
+		changed, err := processFile(args, fileProc, path, content, w)
+		if err != nil {
+			report(fmt.Errorf("%s: %w", path, err))
+		}
+		anyChanged = anyChanged || changed
+	}
+
+	for _, name := range args.files {
+		if args.recursive {
+			args.walker.walk(name, process, report)
+			continue
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			report(err)
+			continue
+		}
+		process(name, content)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func checkFileContent(t *testing.T, name, want string) {
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("%s: want %#v got %#v", name, want, string(got))
+	}
+}
+
+func TestRewriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "f.txt")
+	if err := os.WriteFile(name, []byte("a \r\nb"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("f.txt", link); err != nil {
+		t.Fatal(err)
+	}
+	norm := mustBuild(t, "norm")
+
+	changed, err := rewriteFile(norm, link, []byte("a \r\nb"), ".orig")
+	if !changed || err != nil {
+		t.Fatal("Want", true, nil, "got", changed, err)
+	}
+	checkFileContent(t, name, "a\nb\n")
+	checkFileContent(t, name+".orig", "a \r\nb")
+	if info, err := os.Lstat(link); err != nil ||
+		info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("Want symlink got", info, err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0640 {
+		t.Fatalf("Want %v got %v", os.FileMode(0640), perm)
+	}
+
+	if err := os.Remove(name + ".orig"); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = rewriteFile(norm, name, []byte("a\nb\n"), ".orig")
+	if changed || err != nil {
+		t.Fatal("Want", false, nil, "got", changed, err)
+	}
+	if _, err := os.Stat(name + ".orig"); !os.IsNotExist(err) {
+		t.Fatal("Want no backup got", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatal("Want 2 files got", len(entries))
+	}
+
+	if err := os.WriteFile(name, []byte("a\xff "), 0640); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = rewriteFile(norm, name, []byte("a\xff "), "")
+	if changed || err == nil {
+		t.Fatal("Want", false, "error", "got", changed, err)
+	}
+	checkFileContent(t, name, "a\xff ")
+}
+
+func TestDiffFileWrite(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "f.txt")
+	if err := os.WriteFile(name, []byte("a \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	args, err := parseArgs(testRegistry, []string{"cmd", "-d", "-w", "norm", name},
+		io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := &strings.Builder{}
+	changed, err := processFile(args, registry.Chain(args.runeProcs...),
+		name, []byte("a \n"), builder)
+	if !changed || err != nil {
+		t.Fatal("Want", true, nil, "got", changed, err)
+	}
+	want := "--- " + name + "\t(original)\n+++ " + name + "\t(processed)\n" +
+		"@@ -1 +1 @@\n-a·\n+a\n"
+	if got := builder.String(); got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+	checkFileContent(t, name, "a\n")
+}
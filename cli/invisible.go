@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"io"
+)
+
+// codePointsFlag collects repeated code point flags.
+type codePointsFlag []rune
+
+func (c *codePointsFlag) String() string {
+	return ""
+}
+
+func (c *codePointsFlag) Set(s string) error {
+	r, err := textproc.ParseCodePoint(s)
+	if err != nil {
+		return err
+	}
+	*c = append(*c, r)
+	return nil
+}
+
+// reportInvisible reports each invisible character in the output
+// of runeProc for r, the input named name,
+// except those allowed by -allow and zero width joiners between emoji.
+func reportInvisible(args *cmdArgs, runeProc textproc.RuneProcessor,
+	r io.Reader, name string, w io.Writer) (found bool, err error) {
+	runeCh, errCh := runeProc(textproc.ReadRunes(r))
+	invisible, err := textproc.FindInvisible(runeCh, errCh,
+		&textproc.InvisibleAllowlist{Runes: args.allow, EmojiZWJ: true})
+	if err != nil {
+		return false, err
+	}
+	for _, inv := range invisible {
+		if _, err = fmt.Fprint(w, name, ":", inv, "\n"); err != nil {
+			return
+		}
+	}
+	return len(invisible) > 0, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"io"
+	"text/tabwriter"
+)
+
+// statsJSON is the JSON form of the statistics of an input.
+type statsJSON struct {
+	Name string `json:"name"`
+	*textproc.Stats
+}
+
+// yesNo returns "yes" or "no".
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// writeStats writes the statistics of the output of runeProc
+// for r, the input named name, as text or, with -json, as a JSON line.
+func writeStats(args *cmdArgs, runeProc textproc.RuneProcessor, r io.Reader,
+	name string, w io.Writer) error {
+	s, err := textproc.ReadStats(runeProc(textproc.ReadRunes(r)))
+	if err != nil {
+		return err
+	}
+	if args.json {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(statsJSON{name, s})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprint(tw, name, ":\n")
+	for _, field := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"bytes", s.Bytes},
+		{"runes", s.Runes},
+		{"lines", s.Lines},
+		{"paragraphs", s.Paragraphs},
+		{"words", s.Words},
+		{"longest line", s.LongestLine},
+		{"LF", s.LF},
+		{"CRLF", s.CRLF},
+		{"CR", s.CR},
+		{"trailing white space lines", s.TrailingWhiteSpaceLines},
+		{"tab indented lines", s.TabIndentedLines},
+		{"space indented lines", s.SpaceIndentedLines},
+		{"BOM", yesNo(s.BOM)},
+		{"final newline", yesNo(s.FinalNewline)},
+	} {
+		fmt.Fprintf(tw, "\t%s\t%v\n", field.name, field.value)
+	}
+	return tw.Flush()
+}
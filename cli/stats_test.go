@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteStats(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("\ufeff\tab  cd \r\n\n e"),
+		0666); err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := parseArgs(testRegistry, []string{"cmd", "-stats", path},
+		io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &strings.Builder{}
+	if err := writeStats(args, mustBuild(t, "lf"), strings.NewReader(""),
+		"<stdin>", w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(w.String(), "<stdin>:\n bytes ") {
+		t.Fatalf("Unexpected %#v", w.String())
+	}
+
+	w.Reset()
+	if processFiles(args, mustBuild(t, "lf"), w, func(err error) {
+		t.Fatal(err)
+	}) {
+		t.Fatal("Want no changes")
+	}
+	want := path + `:
+ bytes                      15
+ runes                      13
+ lines                      3
+ paragraphs                 2
+ words                      3
+ longest line               15
+ LF                         2
+ CRLF                       0
+ CR                         0
+ trailing white space lines 1
+ tab indented lines         1
+ space indented lines       1
+ BOM                        yes
+ final newline              no
+`
+	if w.String() != want {
+		t.Fatalf("Want %#v got %#v", want, w.String())
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// globRegexp converts a slash-separated glob pattern to a regular expression.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile("^" + globPattern(glob) + "$")
+}
+
+// globPattern converts a slash-separated glob pattern
+// to an unanchored regular expression.
+//
+// "*" matches any sequence of characters except "/",
+// "?" matches any character except "/",
+// "[…]" and "[!…]" match a character class,
+// "**/" matches zero or more directories,
+// a final "/**" matches everything inside a directory
+// and a backslash escapes the next character.
+func globPattern(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 3
+		case glob[i:] == "/**":
+			re.WriteString("(?:/.*)?")
+			i += 3
+		case strings.HasPrefix(glob[i:], "**"):
+			re.WriteString(".*")
+			i += 2
+		case glob[i] == '*':
+			re.WriteString("[^/]*")
+			i++
+		case glob[i] == '?':
+			re.WriteString("[^/]")
+			i++
+		case glob[i] == '[' && i+2 < len(glob) &&
+			strings.Contains(glob[i+2:], "]"):
+			end := i + 2 + strings.Index(glob[i+2:], "]")
+			class := glob[i+1 : end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end + 1
+		case glob[i] == '\\' && i+1 < len(glob):
+			_, size := utf8.DecodeRuneInString(glob[i+1:])
+			re.WriteString(regexp.QuoteMeta(glob[i+1 : i+1+size]))
+			i += 1 + size
+		default:
+			_, size := utf8.DecodeRuneInString(glob[i:])
+			re.WriteString(regexp.QuoteMeta(glob[i : i+size]))
+			i += size
+		}
+	}
+	return re.String()
+}
+
+// pathGlobRegexp converts a glob for relative paths to a regular expression.
+func pathGlobRegexp(glob string) (*regexp.Regexp, error) {
+	return globRegexp(pathGlob(glob))
+}
+
+// pathGlob converts a glob for relative paths to a glob for the path
+// relative to the root directory.
+// A glob without "/" matches the name of a file or directory at any depth.
+// A leading "/" is ignored.
+func pathGlob(glob string) string {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	return strings.TrimPrefix(glob, "/")
+}
+
+// globsFlag collects repeated glob flags.
+type globsFlag []*regexp.Regexp
+
+func (g *globsFlag) String() string {
+	return ""
+}
+
+func (g *globsFlag) Set(glob string) error {
+	re, err := pathGlobRegexp(glob)
+	if err != nil {
+		return err
+	}
+	*g = append(*g, re)
+	return nil
+}
+
+func (g globsFlag) match(relPath string) bool {
+	for _, re := range g {
+		if re.MatchString(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// A gitignoreRule is a pattern line of a .gitignore file.
+type gitignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// parseGitignore parses the content of a .gitignore file.
+// Invalid patterns are skipped, like git does.
+func parseGitignore(content []byte) ([]*gitignoreRule, error) {
+	var rules []*gitignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := &gitignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		re, err := pathGlobRegexp(line)
+		if err != nil || line == "" {
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// A fileWalker finds the text files to process.
+type fileWalker struct {
+	include, exclude globsFlag
+	// gitignore maps directories to the rules of their .gitignore file.
+	gitignore map[string][]*gitignoreRule
+}
+
+// ignored reports if the .gitignore files in root and in the directories
+// between root and p ignore p.
+// The last matching rule of the deepest .gitignore file decides.
+func (w *fileWalker) ignored(root, p string, isDir bool) bool {
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		rules := w.gitignore[dir]
+		if rel, err := filepath.Rel(dir, p); err == nil {
+			rel = filepath.ToSlash(rel)
+			for i := len(rules) - 1; i >= 0; i-- {
+				if (isDir || !rules[i].dirOnly) &&
+					rules[i].re.MatchString(rel) {
+					return !rules[i].negate
+				}
+			}
+		}
+
+		if dir == root || dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// isBinary reports if content looks like binary data:
+// it has a NUL byte in the first 8000 bytes or is not valid UTF-8.
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(content)
+}
+
+// walk calls fn with the path and content of each file in the tree
+// rooted at root, skipping binary files, files and directories
+// which .gitignore files or the exclude globs match, ".git" directories,
+// and, if there are include globs, files which they do not match.
+// Globs match the slash-separated path relative to root,
+// or the name of root if it is a file.
+// The .gitignore files above root are not read.
+// It calls report for each error and continues the walk,
+// skipping the directories whose .gitignore file it cannot read.
+func (w *fileWalker) walk(root string, fn func(p string, content []byte),
+	report func(error)) {
+	if w.gitignore == nil {
+		w.gitignore = map[string][]*gitignoreRule{}
+	}
+	root = filepath.Clean(root)
+
+	walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry,
+		err error) error {
+		if err != nil {
+			report(err)
+			if d != nil && d.IsDir() && p != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			report(err)
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if p == root && !d.IsDir() {
+			// Globs match the name of a root which is a file.
+			rel = d.Name()
+		}
+
+		if d.IsDir() {
+			if p != root && (d.Name() == ".git" ||
+				w.exclude.match(rel) || w.ignored(root, p, true)) {
+				return fs.SkipDir
+			}
+			name := filepath.Join(p, ".gitignore")
+			content, err := os.ReadFile(name)
+			if err == nil {
+				w.gitignore[p], err = parseGitignore(content)
+				if err != nil {
+					err = fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			if err != nil && !os.IsNotExist(err) {
+				// Without its rules, the directory might have ignored files.
+				report(err)
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || w.exclude.match(rel) ||
+			(len(w.include) > 0 && !w.include.match(rel)) ||
+			w.ignored(root, p, false) {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			report(err)
+			return nil
+		}
+		if !isBinary(content) {
+			fn(p, content)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		report(walkErr)
+	}
+}
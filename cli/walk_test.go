@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"github.com/MihaiB/textproc/v3/registry"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	for _, tc := range []*struct {
+		glob, path string
+		match      bool
+	}{
+		{"*.md", "a.md", true},
+		{"*.md", "d/a.md", false},
+		{"d/*.md", "d/a.md", true},
+		{"?.go", "ab.go", false},
+		{"?.go", "é.go", true},
+		{"[ab].txt", "b.txt", true},
+		{"[!ab].txt", "b.txt", false},
+		{"[!ab].txt", "c.txt", true},
+		{"**/x", "x", true},
+		{"**/x", "a/b/x", true},
+		{"vendor/**", "vendor", true},
+		{"vendor/**", "vendor/a/b", true},
+		{"vendor/**", "vendors", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a**", "ab/c", true},
+		{`\*.md`, "*.md", true},
+		{`\*.md`, "a.md", false},
+		{"a.b", "axb", false},
+		{"[", "[", true},
+	} {
+		re, err := globRegexp(tc.glob)
+		if err != nil {
+			t.Fatal(tc.glob, err)
+		}
+		if got := re.MatchString(tc.path); got != tc.match {
+			t.Fatal(tc.glob, tc.path, "want", tc.match, "got", got)
+		}
+	}
+
+	for glob, path := range map[string]string{
+		"*.md":    "d/e/a.md",
+		"/a.md":   "a.md",
+		"d/*.txt": "d/b.txt",
+	} {
+		re, err := pathGlobRegexp(glob)
+		if err != nil {
+			t.Fatal(glob, err)
+		}
+		if !re.MatchString(path) {
+			t.Fatal(glob, "does not match", path)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	for content, want := range map[string]bool{
+		"":                                 false,
+		"text\n":                           false,
+		"a\x00b":                           true,
+		"a\xffb":                           true,
+		"ø🚲\t\r\n":                         false,
+		strings.Repeat("a", 8000) + "\x00": false,
+	} {
+		if got := isBinary([]byte(content)); got != want {
+			t.Fatalf("%#.20v: want %v got %v", content, want, got)
+		}
+	}
+}
+
+// writeTree creates the files in dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var testTree = map[string]string{
+	".gitignore":       "/b.txt\n# comment\n",
+	".git/config":      "[core]\n",
+	"a.md":             "a \n",
+	"b.txt":            "b\n",
+	"c.txt":            "c\n",
+	"bin.md":           "\x00\x01",
+	"latin1.md":        "caf\xe9\n",
+	"vendor/v.md":      "v\n",
+	"sub/.gitignore":   "*.log\n!keep.log\ngen/\n",
+	"sub/c.md":         "c\n",
+	"sub/x.log":        "x\n",
+	"sub/keep.log":     "k\n",
+	"sub/gen/g.md":     "g\n",
+	"sub/deep/gen":     "file named gen\n",
+	"sub/deep/b.txt":   "not ignored by /b.txt\n",
+	"sub/vendor/v2.md": "v2\n",
+}
+
+func walkedFiles(t *testing.T, w *fileWalker, root string) []string {
+	var got []string
+	w.walk(root, func(p string, content []byte) {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}, func(err error) {
+		t.Fatal(err)
+	})
+	sort.Strings(got)
+	return got
+}
+
+func TestFileWalker(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, testTree)
+
+	w := &fileWalker{}
+	if err := w.exclude.Set("vendor/**"); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(walkedFiles(t, w, dir), " ")
+	want := ".gitignore a.md c.txt sub/.gitignore sub/c.md sub/deep/b.txt" +
+		" sub/deep/gen sub/keep.log sub/vendor/v2.md"
+	if got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+
+	w = &fileWalker{}
+	if err := w.include.Set("*.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.exclude.Set("vendor"); err != nil {
+		t.Fatal(err)
+	}
+	got = strings.Join(walkedFiles(t, w, filepath.Join(dir, "sub")), " ")
+	want = "c.md"
+	if got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+
+	long := t.TempDir()
+	writeTree(t, long, map[string]string{
+		"a.md":            "a\n",
+		"long/.gitignore": strings.Repeat("x", bufio.MaxScanTokenSize) + "\n",
+		"long/b.md":       "b\n",
+	})
+	var walked []string
+	var errs []error
+	(&fileWalker{}).walk(long, func(p string, content []byte) {
+		walked = append(walked, filepath.Base(p))
+	}, func(err error) {
+		errs = append(errs, err)
+	})
+	if strings.Join(walked, " ") != "a.md" || len(errs) != 1 ||
+		!errors.Is(errs[0], bufio.ErrTooLong) {
+		t.Fatal("Want [a.md] and", bufio.ErrTooLong, "got", walked, errs)
+	}
+
+	for name, want := range map[string]string{"a.md": ".", "c.txt": ""} {
+		got = strings.Join(walkedFiles(t, w, filepath.Join(dir, name)), " ")
+		if got != want {
+			t.Errorf("%v: want %#v got %#v", name, want, got)
+		}
+	}
+}
+
+func TestProcessFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, testTree)
+
+	args, err := parseArgs(testRegistry,
+		[]string{"cmd", "-r", "-check", "-include",
+			"*.md", "norm", dir, filepath.Join(dir, "missing")}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	var errs []string
+	changed := processFiles(args, registry.Chain(args.runeProcs...),
+		builder, func(err error) {
+			errs = append(errs, err.Error())
+		})
+	if !changed {
+		t.Fatal("Want", true, "got", changed)
+	}
+	want := filepath.Join(dir, "a.md") + ":1:2: processing changes the input\n"
+	if got := builder.String(); got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "missing") {
+		t.Fatal("Want 1 error about missing got", errs)
+	}
+
+	args, err = parseArgs(testRegistry, []string{"cmd", "-w", "norm",
+		filepath.Join(dir, "latin1.md"), filepath.Join(dir, "a.md")},
+		io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = nil
+	changed = processFiles(args, registry.Chain(args.runeProcs...),
+		builder, func(err error) {
+			errs = append(errs, err.Error())
+		})
+	if !changed {
+		t.Fatal("Want", true, "got", changed)
+	}
+	wantErr := filepath.Join(dir, "latin1.md") + ": invalid UTF-8"
+	if len(errs) != 1 || errs[0] != wantErr {
+		t.Fatal("Want", wantErr, "got", errs)
+	}
+	checkFileContent(t, filepath.Join(dir, "a.md"), "a\n")
+}
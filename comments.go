@@ -0,0 +1,89 @@
+package textproc
+
+import (
+	"strings"
+	"unicode"
+)
+
+func isCommentLine(line []rune, prefix string) bool {
+	return strings.HasPrefix(
+		strings.TrimLeftFunc(string(line), unicode.IsSpace), prefix)
+}
+
+// SortLFLinesWithCommentsI reads the content of all lines using
+// ReadLFLineContent and sorts them in case-insensitive order,
+// like SortLFLinesI, except for comment lines which start with prefix
+// after optional white space.
+// The header stays at the start: it consists of the comment and blank
+// lines at the start of the input up to the last blank line
+// before the first other line, or of the comment lines at the start
+// if there is no such blank line.
+// Blank lines stay in place and separate groups of lines
+// which are sorted separately.
+// A run of comment lines moves together with the line after it,
+// and comment lines at the end of a group stay at its end.
+// It adds "\n" after each line.
+func SortLFLinesWithCommentsI(prefix string) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		lineIn, errIn := ReadLFLineContent(runeIn, errIn)
+		runeOut := make(chan rune)
+
+		go func() {
+			writeLine := func(line []rune) {
+				writeRunes(runeOut, line)
+				runeOut <- '\n'
+			}
+
+			var entries, keys [][]rune
+			var entry []rune
+			writeGroup := func() {
+				for _, i := range sortIndicesI(keys) {
+					writeRunes(runeOut, entries[i])
+				}
+				writeRunes(runeOut, entry)
+				entries, keys, entry = nil, nil, nil
+			}
+
+			var lines [][]rune
+			for line := range lineIn {
+				lines = append(lines, line)
+			}
+			headerEnd, blankEnd := 0, 0
+			for i, line := range lines {
+				if isBlank(line) {
+					blankEnd = i + 1
+				} else if !isCommentLine(line, prefix) {
+					break
+				}
+				headerEnd = i + 1
+			}
+			if blankEnd > 0 {
+				headerEnd = blankEnd
+			}
+
+			for _, line := range lines[:headerEnd] {
+				writeLine(line)
+			}
+			for _, line := range lines[headerEnd:] {
+				switch {
+				case isBlank(line):
+					writeGroup()
+					writeLine(line)
+				default:
+					entry = append(entry, line...)
+					entry = append(entry, '\n')
+					if !isCommentLine(line, prefix) {
+						entries = append(entries, entry)
+						keys = append(keys, line)
+						entry = nil
+					}
+				}
+			}
+			writeGroup()
+			close(runeOut)
+		}()
+
+		return runeOut, errIn
+	}
+}
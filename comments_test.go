@@ -0,0 +1,34 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestSortLFLinesWithCommentsI(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":       {"", nil},
+		"b\nA\n": {"A\nb\n", nil},
+		"# Header\n# text\n\n# the z\nz\ny\n# for x\n  # x\nx\n# end": {
+			"# Header\n# text\n\n# for x\n  # x\nx\ny\n# the z\nz\n# end\n",
+			nil},
+		"# Header\nb\na\n": {"# Header\na\nb\n", nil},
+		"# c\nc\n\n# b\nb\n\n# a\na\n": {
+			"# c\nc\n\n# b\nb\n\n# a\na\n", nil},
+		"d\nc\n\nb\n# a\na\n# end\n \nz\ny\n": {
+			"c\nd\n\n# a\na\nb\n# end\n \ny\nz\n", nil},
+		"# only\n\n# comments\n": {"# only\n\n# comments\n", nil},
+		"\n\n# z\nz\na\n":        {"\n\na\n# z\nz\n", nil},
+		"# z\nz\na\n# b\nb\xff\n": {"# z\na\nz\n# b\n",
+			textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortLFLinesWithCommentsI("#"),
+		testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"x\n// y\ny\n#w\nw\n": {"#w\nw\nx\n// y\ny\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortLFLinesWithCommentsI("//"),
+		testcases)
+}
@@ -0,0 +1,253 @@
+package textproc
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnterminatedCSVQuote is the error returned
+// when the input ends inside a quoted CSV field.
+var ErrUnterminatedCSVQuote = errors.New("unterminated quoted CSV field")
+
+// ReadCSVRecords returns a Tokenizer which reads the content of each
+// RFC 4180 record whose fields are separated by comma.
+// The content is the raw text of the record, with quotes,
+// and does not include the record terminator.
+//
+// Records are terminated by "\n" or "\r\n" outside quoted fields,
+// so a quoted field can contain line terminators.
+// Empty lines are skipped.
+// A quote inside an unquoted field is an ordinary character.
+// It fails with ErrUnterminatedCSVQuote if the input ends in a quoted field.
+func ReadCSVRecords(comma rune) Tokenizer {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan []rune, <-chan error) {
+		tokenOut, errOut := make(chan []rune), make(chan error)
+
+		go func() {
+			var record []rune
+			fieldStart, inQuotes, closedQuote := true, false, false
+
+			endRecord := func() {
+				if n := len(record); n > 0 && record[n-1] == '\r' {
+					record = record[:n-1]
+				}
+				if len(record) > 0 {
+					tokenOut <- record
+				}
+				record = nil
+			}
+
+			for r := range runeIn {
+				record = append(record, r)
+
+				if inQuotes {
+					if r == '"' {
+						inQuotes, closedQuote = false, true
+					}
+					continue
+				}
+				if r == '"' && (fieldStart || closedQuote) {
+					inQuotes, fieldStart, closedQuote = true, false, false
+					continue
+				}
+				closedQuote = false
+
+				if r == '\n' {
+					record = record[:len(record)-1]
+					endRecord()
+					fieldStart = true
+					continue
+				}
+				fieldStart = r == comma
+			}
+
+			err := <-errIn
+			if err == nil && inQuotes {
+				err = ErrUnterminatedCSVQuote
+			}
+			if err == nil {
+				endRecord()
+			}
+			close(tokenOut)
+			errOut <- err
+			close(errOut)
+		}()
+
+		return tokenOut, errOut
+	}
+}
+
+// parseCSVRecord returns the unquoted fields
+// of a record read by ReadCSVRecords.
+func parseCSVRecord(record []rune, comma rune) []string {
+	var fields []string
+	var field strings.Builder
+	fieldStart, inQuotes, closedQuote := true, false, false
+
+	for _, r := range record {
+		if inQuotes {
+			if r == '"' {
+				inQuotes, closedQuote = false, true
+			} else {
+				field.WriteRune(r)
+			}
+			continue
+		}
+		if r == '"' && (fieldStart || closedQuote) {
+			if closedQuote {
+				field.WriteRune(r)
+			}
+			inQuotes, fieldStart, closedQuote = true, false, false
+			continue
+		}
+		closedQuote = false
+
+		if r == comma {
+			fields = append(fields, field.String())
+			field.Reset()
+			fieldStart = true
+			continue
+		}
+		field.WriteRune(r)
+		fieldStart = false
+	}
+
+	return append(fields, field.String())
+}
+
+// formatCSVRecord quotes the fields which need quoting, or all fields
+// if quoteAll is true, and joins them with comma.
+func formatCSVRecord(fields []string, comma rune, quoteAll bool) []rune {
+	var record []rune
+	for i, field := range fields {
+		if i > 0 {
+			record = append(record, comma)
+		}
+		if !quoteAll && !strings.ContainsAny(field, "\"\r\n"+string(comma)) {
+			record = append(record, []rune(field)...)
+			continue
+		}
+		record = append(record, '"')
+		record = append(record,
+			[]rune(strings.ReplaceAll(field, `"`, `""`))...)
+		record = append(record, '"')
+	}
+	return record
+}
+
+// writeRecords reads the records using ReadCSVRecords
+// and writes the result of fn on each record followed by "\n".
+func writeRecords(runeIn <-chan rune, errIn <-chan error, comma rune,
+	fn func(record []rune) []rune) (<-chan rune, <-chan error) {
+	recordIn, errIn := ReadCSVRecords(comma)(runeIn, errIn)
+	runeOut := make(chan rune)
+
+	go func() {
+		for record := range recordIn {
+			writeRunes(runeOut, fn(record))
+			runeOut <- '\n'
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// SortCSVRecordsI reads all records using ReadCSVRecords,
+// sorts them in case-insensitive order of the field in column
+// (counting from 1, a missing field sorts as empty)
+// and adds "\n" after each.
+// If header is true the first record stays first.
+func SortCSVRecordsI(comma rune, column int, header bool) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		recordIn, errIn := ReadCSVRecords(comma)(runeIn, errIn)
+		runeOut := make(chan rune)
+
+		go func() {
+			var records, keys [][]rune
+			inHeader := header
+			for record := range recordIn {
+				if inHeader {
+					writeRunes(runeOut, record)
+					runeOut <- '\n'
+					inHeader = false
+					continue
+				}
+
+				var key []rune
+				if fields := parseCSVRecord(record, comma); column >= 1 &&
+					column <= len(fields) {
+					key = []rune(fields[column-1])
+				}
+				records = append(records, record)
+				keys = append(keys, key)
+			}
+
+			for _, i := range sortIndicesI(keys) {
+				writeRunes(runeOut, records[i])
+				runeOut <- '\n'
+			}
+			close(runeOut)
+		}()
+
+		return runeOut, errIn
+	}
+}
+
+// NormalizeCSV reads the records using ReadCSVRecords,
+// separates the fields with outComma instead of comma,
+// quotes only the fields which need quoting (or all if quoteAll is true)
+// and adds "\n" after each record.
+func NormalizeCSV(comma, outComma rune, quoteAll bool) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return writeRecords(runeIn, errIn, comma,
+			func(record []rune) []rune {
+				return formatCSVRecord(parseCSVRecord(record, comma),
+					outComma, quoteAll)
+			})
+	}
+}
+
+var tsvEscaper = strings.NewReplacer(
+	`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+var tsvUnescaper = strings.NewReplacer(
+	`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+
+// ConvertCSVToTSV reads the records using ReadCSVRecords
+// and writes them as tab-separated values, each followed by "\n".
+// Backslash, tab, line feed and carriage return in fields
+// are escaped as `\\`, `\t`, `\n` and `\r`.
+func ConvertCSVToTSV(comma rune) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return writeRecords(runeIn, errIn, comma,
+			func(record []rune) []rune {
+				fields := parseCSVRecord(record, comma)
+				for i := range fields {
+					fields[i] = tsvEscaper.Replace(fields[i])
+				}
+				return []rune(strings.Join(fields, "\t"))
+			})
+	}
+}
+
+// ConvertTSVToCSV converts each line of tab-separated values
+// to a CSV record with fields separated by comma,
+// reversing the escapes of ConvertCSVToTSV.
+// Lines are terminated by "\n".
+func ConvertTSVToCSV(comma rune) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			fields := strings.Split(string(line), "\t")
+			for i := range fields {
+				fields[i] = tsvUnescaper.Replace(fields[i])
+			}
+			return formatCSVRecord(fields, comma, false)
+		})
+	}
+}
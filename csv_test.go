@@ -0,0 +1,80 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestReadCSVRecords(t *testing.T) {
+	testcases := internal.TokenizerTestCases{
+		"":                {nil, nil},
+		"a,b\r\nc,d":      {[]string{"a,b", "c,d"}, nil},
+		"\n\na\n\n":       {[]string{"a"}, nil},
+		"\"x\ny\",z\nw\n": {[]string{"\"x\ny\",z", "w"}, nil},
+		"\"a\"\"\n\"\n":   {[]string{"\"a\"\"\n\""}, nil},
+		"a\"b\nc\n":       {[]string{"a\"b", "c"}, nil},
+		"1,\"\r\n\"\r\n":  {[]string{"1,\"\r\n\""}, nil},
+		"a\n\"b\nc":       {[]string{"a"}, textproc.ErrUnterminatedCSVQuote},
+		"a\n\"b\xff\"\n":  {[]string{"a"}, textproc.ErrInvalidUTF8},
+	}
+	internal.CheckTokenizer(t, textproc.ReadCSVRecords(','), testcases)
+
+	testcases = internal.TokenizerTestCases{
+		"a;\"b\"\n": {[]string{"a;\"b\""}, nil},
+		"a,\"b\n":   {[]string{"a,\"b"}, nil},
+	}
+	internal.CheckTokenizer(t, textproc.ReadCSVRecords(';'), testcases)
+}
+
+func TestSortCSVRecordsI(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                    {"", nil},
+		"name,n\nb,1\nA,2\n":  {"name,n\nA,2\nb,1\n", nil},
+		"h\n\"z\nz\",1\na,2":  {"h\na,2\n\"z\nz\",1\n", nil},
+		"h\r\nc\r\n\r\nb":     {"h\nb\nc\n", nil},
+		"h\nc\n\"b\"\"\",\"a": {"h\nc\n", textproc.ErrUnterminatedCSVQuote},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortCSVRecordsI(',', 1, true),
+		testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"x,3,C\ny,1\nz,2,a\n": {"y,1\nz,2,a\nx,3,C\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortCSVRecordsI(',', 3, false),
+		testcases)
+}
+
+func TestNormalizeCSV(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                            {"", nil},
+		"\"a\",b\r\n\"c;\",\"d\"\"\"": {"a;b\n\"c;\";\"d\"\"\"\n", nil},
+		"\"x\ny\",,\"\"\n":            {"\"x\ny\";;\n", nil},
+		"a\n\"b":                      {"a\n", textproc.ErrUnterminatedCSVQuote},
+	}
+	internal.CheckRuneProcessor(t, textproc.NormalizeCSV(',', ';', false),
+		testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"a,\"b\"\"\",\n": {"\"a\",\"b\"\"\",\"\"\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.NormalizeCSV(',', ',', true),
+		testcases)
+}
+
+func TestConvertCSVToTSV(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                                 {"", nil},
+		"a,\"b,c\"\n\"1\t2\",\"x\r\ny\\\"": {"a\tb,c\n1\\t2\tx\\r\\ny\\\\\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.ConvertCSVToTSV(','), testcases)
+}
+
+func TestConvertTSVToCSV(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                         {"", nil},
+		"a\tb,c\n1\\t2\tx\\ny\\\\": {"a,\"b,c\"\n1\t2,\"x\ny\\\"", nil},
+		"q\"\t\\\\t\n":             {"\"q\"\"\",\\t\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.ConvertTSVToCSV(','), testcases)
+}
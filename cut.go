@@ -0,0 +1,142 @@
+package textproc
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFieldList is the error returned
+// when a field list cannot be parsed.
+var ErrInvalidFieldList = errors.New("invalid field list")
+
+// A FieldSplitter splits a line into fields.
+type FieldSplitter = func(line string) []string
+
+// SplitFieldsOn returns a FieldSplitter which splits on delim.
+func SplitFieldsOn(delim string) FieldSplitter {
+	return func(line string) []string {
+		return strings.Split(line, delim)
+	}
+}
+
+// SplitFieldsOnRegexp returns a FieldSplitter
+// which splits on the matches of re.
+func SplitFieldsOnRegexp(re *regexp.Regexp) FieldSplitter {
+	return func(line string) []string {
+		return re.Split(line, -1)
+	}
+}
+
+// SplitRunes is a FieldSplitter which makes each rune a field.
+func SplitRunes(line string) []string {
+	var fields []string
+	for _, r := range line {
+		fields = append(fields, string(r))
+	}
+	return fields
+}
+
+// SplitDisplayColumns is a FieldSplitter which makes each display column
+// a field.
+//
+// A wide character is the field of its first column
+// and the following fields are empty.
+// A zero-width character (such as a combining mark)
+// belongs to the field of the preceding character.
+// Tabs advance to the next multiple of 8 columns.
+func SplitDisplayColumns(line string) []string {
+	var fields []string
+	var zeroWidth string
+	col, lastRune := 0, -1
+	for _, r := range line {
+		next := advanceColumn(col, r)
+		if next == col {
+			if lastRune >= 0 {
+				fields[lastRune] += string(r)
+			} else {
+				zeroWidth += string(r)
+			}
+			continue
+		}
+
+		lastRune = len(fields)
+		fields = append(fields, zeroWidth+string(r))
+		zeroWidth = ""
+		for col++; col < next; col++ {
+			fields = append(fields, "")
+		}
+	}
+	if zeroWidth != "" {
+		fields = append(fields, zeroWidth)
+	}
+	return fields
+}
+
+// A FieldRange selects the fields from First to Last, counting from 1.
+// Last 0 selects the fields from First to the end of the line.
+type FieldRange struct {
+	First, Last int
+}
+
+// ParseFieldRanges parses a comma-separated list of fields or ranges,
+// like cut's "-f": "N", "N-M", "N-" or "-M".
+// The ranges can be given in any order and can overlap.
+func ParseFieldRanges(list string) ([]FieldRange, error) {
+	var ranges []FieldRange
+	for _, item := range strings.Split(list, ",") {
+		bounds := strings.SplitN(item, "-", 2)
+		if len(bounds) == 1 {
+			bounds = append(bounds, bounds[0])
+		}
+
+		var fr FieldRange
+		var err error
+		if bounds[0] == "" {
+			fr.First = 1
+		} else if fr.First, err = strconv.Atoi(bounds[0]); err != nil {
+			return nil, ErrInvalidFieldList
+		}
+		if bounds[1] != "" {
+			if fr.Last, err = strconv.Atoi(bounds[1]); err != nil ||
+				fr.Last < 1 {
+				return nil, ErrInvalidFieldList
+			}
+		} else if bounds[0] == "" {
+			return nil, ErrInvalidFieldList
+		}
+
+		if fr.First < 1 || (fr.Last != 0 && fr.Last < fr.First) {
+			return nil, ErrInvalidFieldList
+		}
+		ranges = append(ranges, fr)
+	}
+	return ranges, nil
+}
+
+// CutLFLines splits each line using split
+// and outputs the fields selected by ranges, in the order of ranges,
+// joined by outDelim.
+// Fields beyond the end of a line are ignored.
+// Lines are terminated by "\n".
+func CutLFLines(split FieldSplitter, ranges []FieldRange,
+	outDelim string) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			fields := split(string(line))
+			var selected []string
+			for _, fr := range ranges {
+				last := fr.Last
+				if last == 0 || last > len(fields) {
+					last = len(fields)
+				}
+				for i := fr.First; i <= last; i++ {
+					selected = append(selected, fields[i-1])
+				}
+			}
+			return []rune(strings.Join(selected, outDelim))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseFieldRanges(t *testing.T) {
+	for _, list := range []string{
+		"", "0", "a", "1,", "-", "3-2", "-0", "1-2-3", "2--3", "-2-",
+	} {
+		if ranges, err := textproc.ParseFieldRanges(list); err == nil {
+			t.Fatalf("Want error for %#v got %#v", list, ranges)
+		}
+	}
+
+	for list, want := range map[string][]textproc.FieldRange{
+		"1":         {{1, 1}},
+		"3,1":       {{3, 3}, {1, 1}},
+		"2-4,-3,5-": {{2, 4}, {1, 3}, {5, 0}},
+		"10-10,1-1": {{10, 10}, {1, 1}},
+	} {
+		got, err := textproc.ParseFieldRanges(list)
+		if err != nil {
+			t.Fatalf("Want nil for %#v got %v", list, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Want %#v got %#v", want, got)
+		}
+	}
+}
+
+func TestSplitRunes(t *testing.T) {
+	for in, want := range map[string][]string{
+		"":    nil,
+		"a":   {"a"},
+		"xé🐁": {"x", "é", "🐁"},
+	} {
+		if got := textproc.SplitRunes(in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Want %#v got %#v", want, got)
+		}
+	}
+}
+
+func TestSplitDisplayColumns(t *testing.T) {
+	for in, want := range map[string][]string{
+		"":              nil,
+		"ab":            {"a", "b"},
+		"日x":            {"日", "", "x"},
+		"e\u0301f":      {"e\u0301", "f"},
+		"\u0301a":       {"\u0301a"},
+		"\u0301":        {"\u0301"},
+		"ab\tc":         {"a", "b", "\t", "", "", "", "", "", "c"},
+		"\u200b🐁\u200b": {"\u200b🐁\u200b", ""},
+	} {
+		got := textproc.SplitDisplayColumns(in)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Want %#v got %#v", want, got)
+		}
+	}
+}
+
+func TestCutLFLines(t *testing.T) {
+	ranges, err := textproc.ParseFieldRanges("3,1,5-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := internal.RuneProcessorTestCases{
+		"":                    {"", nil},
+		"\n":                  {"\n", nil},
+		"a\tb\tc\n1\t2":       {"c,a\n1", nil},
+		"a\tb\tc\td\te\tf\n":  {"c,a,e,f\n", nil},
+		"a\tb\tc\nd\te\xff\n": {"c,a\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.CutLFLines(textproc.SplitFieldsOn("\t"), ranges, ","),
+		testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"a  b c\n":  {"c a\n", nil},
+		"1 2 3 4 5": {"3 1 5", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.CutLFLines(
+		textproc.SplitFieldsOnRegexp(regexp.MustCompile(" +")),
+		ranges, " "), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"abcdef\nxy\n": {"caef\nx\n", nil},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.CutLFLines(textproc.SplitRunes, ranges, ""),
+		testcases)
+
+	ranges, err = textproc.ParseFieldRanges("2-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases = internal.RuneProcessorTestCases{
+		"日本語\nabc\n": {"本\nbc\n", nil},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.CutLFLines(textproc.SplitDisplayColumns, ranges, ""),
+		testcases)
+}
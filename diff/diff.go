@@ -0,0 +1,243 @@
+// Package diff compares texts line by line.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An Op is the kind of an Edit.
+type Op int
+
+// Edit kinds.
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// An Edit is a line of both texts, a line deleted from the first text
+// or a line inserted from the second text.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// SplitLines splits text after each "\n".
+// Each line keeps its "\n" terminator; the last line may have none.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns a shortest sequence of edits transforming a into b,
+// using the linear space variant of the Myers diff algorithm.
+func Lines(a, b []string) []Edit {
+	return appendEdits(nil, a, b)
+}
+
+// appendEdits appends a shortest sequence of edits transforming a into b.
+// It splits the texts at a middle snake and recurses on both sides.
+func appendEdits(edits []Edit, a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	edits = appendOp(edits, Equal, a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		edits = appendOp(edits, Insert, b)
+	case len(b) == 0:
+		edits = appendOp(edits, Delete, a)
+	default:
+		x0, y0, x1, y1 := middleSnake(a, b)
+		edits = appendEdits(edits, a[:x0], b[:y0])
+		edits = appendOp(edits, Equal, a[x0:x1])
+		edits = appendEdits(edits, a[x1:], b[y1:])
+	}
+	return appendOp(edits, Equal, common)
+}
+
+// appendOp appends an edit with op for each line.
+func appendOp(edits []Edit, op Op, lines []string) []Edit {
+	for _, line := range lines {
+		edits = append(edits, Edit{op, line})
+	}
+	return edits
+}
+
+// middleSnake returns the snake from (x0, y0) to (x1, y1)
+// in the middle of a shortest edit path transforming a into b,
+// searching from both ends at once.
+// The first and last lines of a and b must differ.
+func middleSnake(a, b []string) (x0, y0, x1, y1 int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	// forward[offset+k] is the furthest x reached on diagonal k from the
+	// start; backward[offset+k] is the furthest distance from the end
+	// reached on diagonal delta-k.
+	forward, backward := make([]int, 2*offset+1), make([]int, 2*offset+1)
+
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			x := furthest(forward, offset, k, d)
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			forward[offset+k] = x
+			if rk := delta - k; odd && rk >= -(d-1) && rk <= d-1 &&
+				x+backward[offset+rk] >= n {
+				return startX, startY, x, y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			x := furthest(backward, offset, k, d)
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x, y = x+1, y+1
+			}
+			backward[offset+k] = x
+			if fk := delta - k; !odd && fk >= -d && fk <= d &&
+				x+forward[offset+fk] >= n {
+				return n - x, m - y, n - startX, m - startY
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
+
+// furthest returns the x on diagonal k after one more edit from the
+// furthest points v reached on the neighbouring diagonals with d-1 edits.
+func furthest(v []int, offset, k, d int) int {
+	if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+		return v[offset+k+1]
+	}
+	return v[offset+k-1] + 1
+}
+
+// hunks returns the [start, end) ranges of edits forming the hunks
+// of a unified diff with context lines around each change.
+func hunks(edits []Edit, context int) [][2]int {
+	var ranges [][2]int
+	for i, edit := range edits {
+		if edit.Op == Equal {
+			continue
+		}
+		if n := len(ranges); n > 0 && i-ranges[n-1][1] <= 2*context {
+			ranges[n-1][1] = i + 1
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		ranges = append(ranges, [2]int{start, i + 1})
+	}
+
+	for i := range ranges {
+		if ranges[i][1] += context; ranges[i][1] > len(edits) {
+			ranges[i][1] = len(edits)
+		}
+	}
+	return ranges
+}
+
+// hunkRange formats the start and length of a hunk in one text.
+func hunkRange(before, length int) string {
+	start := before + 1
+	if length == 0 {
+		start = before
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprint(start, ",", length)
+}
+
+// Unified formats the differences between texts as a unified diff.
+type Unified struct {
+	// Context is the number of unchanged lines around each change.
+	Context int
+	// Mark, if not nil, transforms the deleted and inserted lines,
+	// without their "\n" terminator, before they are written.
+	Mark func(line string) string
+}
+
+// Write writes the unified diff transforming lines a, named aName,
+// into lines b, named bName.
+// It writes nothing if a and b are identical.
+func (u *Unified) Write(w io.Writer, aName, bName string, a, b []string) (
+	err error) {
+	edits := Lines(a, b)
+	ranges := hunks(edits, u.Context)
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprint(&sb, "--- ", aName, "\n+++ ", bName, "\n")
+
+	// Lines of a and b before each edit.
+	aBefore, bBefore := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for i, edit := range edits {
+		aBefore[i+1], bBefore[i+1] = aBefore[i], bBefore[i]
+		if edit.Op != Insert {
+			aBefore[i+1]++
+		}
+		if edit.Op != Delete {
+			bBefore[i+1]++
+		}
+	}
+
+	for _, r := range ranges {
+		start, end := r[0], r[1]
+		fmt.Fprint(&sb, "@@ -",
+			hunkRange(aBefore[start], aBefore[end]-aBefore[start]), " +",
+			hunkRange(bBefore[start], bBefore[end]-bBefore[start]), " @@\n")
+
+		for _, edit := range edits[start:end] {
+			line := strings.TrimSuffix(edit.Line, "\n")
+			prefix := " "
+			if edit.Op != Equal {
+				prefix = "-"
+				if edit.Op == Insert {
+					prefix = "+"
+				}
+				if u.Mark != nil {
+					line = u.Mark(line)
+				}
+			}
+			sb.WriteString(prefix + line + "\n")
+			if !strings.HasSuffix(edit.Line, "\n") {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return
+}
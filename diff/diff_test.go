@@ -0,0 +1,146 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	for in, want := range map[string][]string{
+		"":        nil,
+		"a":       {"a"},
+		"a\n":     {"a\n"},
+		"a\n\nb":  {"a\n", "\n", "b"},
+		"\r\nx\n": {"\r\n", "x\n"},
+	} {
+		if got := SplitLines(in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%#v: want %#v got %#v", in, want, got)
+		}
+	}
+}
+
+// apply returns the two texts described by edits.
+func apply(edits []Edit) (a, b []string) {
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			a = append(a, edit.Line)
+		}
+		if edit.Op != Delete {
+			b = append(b, edit.Line)
+		}
+	}
+	return
+}
+
+func TestLines(t *testing.T) {
+	for _, tc := range []*struct {
+		a, b    string
+		changes int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abcabba", "cbabac", 5},
+		{"abcdef", "abxdef", 2},
+		{"xaaa", "aaay", 2},
+	} {
+		a, b := strings.Split(tc.a, ""), strings.Split(tc.b, "")
+		edits := Lines(a, b)
+
+		gotA, gotB := apply(edits)
+		if strings.Join(gotA, "") != tc.a || strings.Join(gotB, "") != tc.b {
+			t.Fatal(tc.a, tc.b, "got", gotA, gotB)
+		}
+		changes := 0
+		for _, edit := range edits {
+			if edit.Op != Equal {
+				changes++
+			}
+		}
+		if changes != tc.changes {
+			t.Fatal(tc.a, tc.b, "want", tc.changes, "changes got", changes)
+		}
+	}
+}
+
+// numbered returns n lines made of prefix and the line number.
+func numbered(prefix string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = prefix + strconv.Itoa(i) + "\n"
+	}
+	return lines
+}
+
+func TestLinesLarge(t *testing.T) {
+	const n = 5000
+	mixed := numbered("a", n)
+	for i := 0; i < n; i += 2 {
+		mixed[i] = "b\n"
+	}
+	for _, tc := range []*struct {
+		a, b    []string
+		changes int
+	}{
+		{numbered("a", n), numbered("b", n), 2 * n},
+		{numbered("a", n), mixed, n},
+	} {
+		edits := Lines(tc.a, tc.b)
+		gotA, gotB := apply(edits)
+		if !reflect.DeepEqual(gotA, tc.a) || !reflect.DeepEqual(gotB, tc.b) {
+			t.Fatal("Edits do not transform a into b")
+		}
+		if changes := len(edits)*2 - len(tc.a) - len(tc.b); changes != tc.changes {
+			t.Fatal("Want", tc.changes, "changes got", changes)
+		}
+	}
+}
+
+func BenchmarkLines(b *testing.B) {
+	x, y := numbered("a", 5000), numbered("b", 5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Lines(x, y)
+	}
+}
+
+func TestUnifiedWrite(t *testing.T) {
+	for _, tc := range []*struct {
+		a, b    string
+		context int
+		mark    bool
+		want    string
+	}{
+		{"a\nb\n", "a\nb\n", 3, false, ""},
+		{"a\nb\nc\n", "a\nB\nc\n", 3, false,
+			"--- x\n+++ y\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"1\n2\n3\n4\n5\n6\n7\n8\n9\n", "1\n2\nx\n4\n5\n6\n7\n8\ny\n", 1, false,
+			"--- x\n+++ y\n@@ -2,3 +2,3 @@\n 2\n-3\n+x\n 4\n" +
+				"@@ -8,2 +8,2 @@\n 8\n-9\n+y\n"},
+		{"1\n2\n3\n4\n", "1\nx\n3\ny\n", 1, false,
+			"--- x\n+++ y\n@@ -1,4 +1,4 @@\n 1\n-2\n+x\n 3\n-4\n+y\n"},
+		{"", "a\n", 3, false, "--- x\n+++ y\n@@ -0,0 +1 @@\n+a\n"},
+		{"a\nb", "a\nb\n", 0, false, "--- x\n+++ y\n@@ -2 +2 @@\n-b\n" +
+			"\\ No newline at end of file\n+b\n"},
+		{"a\nb \r\n", "a\nb\n", 3, true,
+			"--- x\n+++ y\n@@ -1,2 +1,2 @@\n a\n-[b \r]\n+[b]\n"},
+	} {
+		u := &Unified{Context: tc.context}
+		if tc.mark {
+			u.Mark = func(line string) string {
+				return "[" + line + "]"
+			}
+		}
+		sb := &strings.Builder{}
+		err := u.Write(sb, "x", "y", SplitLines(tc.a), SplitLines(tc.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sb.String(); got != tc.want {
+			t.Fatalf("Want %#v got %#v", tc.want, got)
+		}
+	}
+}
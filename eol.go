@@ -0,0 +1,135 @@
+package textproc
+
+// convertLineTerminators converts "\r", "\n" and "\r\n" to term.
+func convertLineTerminators(runeIn <-chan rune, errIn <-chan error,
+	term []rune) (<-chan rune, <-chan error) {
+	runeIn, errIn = ConvertLineTerminatorsToLF(runeIn, errIn)
+	runeOut := make(chan rune)
+
+	go func() {
+		for r := range runeIn {
+			if r == '\n' {
+				writeRunes(runeOut, term)
+			} else {
+				runeOut <- r
+			}
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// ConvertLineTerminatorsToCRLF converts "\r", "\n" and "\r\n" to "\r\n".
+func ConvertLineTerminatorsToCRLF(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	return convertLineTerminators(runeIn, errIn, []rune{'\r', '\n'})
+}
+
+// ConvertLineTerminatorsToCR converts "\n" and "\r\n" to "\r".
+func ConvertLineTerminatorsToCR(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	return convertLineTerminators(runeIn, errIn, []rune{'\r'})
+}
+
+// KeepLineTerminators returns a processor which runs p
+// with the line terminators of the input converted to "\n"
+// and converts the "\n" of the output of p back:
+// the n-th one to the n-th line terminator of the input
+// and those after the last to the last ("\n" if there is none).
+// So processors which keep the lines in place,
+// like TrimLFTrailingWhiteSpace, keep mixed line terminators.
+// It reads the entire input before running p.
+func KeepLineTerminators(p RuneProcessor) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		runeOut, errOut := make(chan rune), make(chan error)
+
+		go func() {
+			var text []rune
+			var terms [][]rune
+			afterCR := false
+			for r := range runeIn {
+				switch {
+				case r == '\n' && afterCR:
+					terms[len(terms)-1] = []rune{'\r', '\n'}
+				case r == '\r', r == '\n':
+					terms = append(terms, []rune{r})
+					text = append(text, '\n')
+				default:
+					text = append(text, r)
+				}
+				afterCR = r == '\r'
+			}
+
+			lfIn, lfErr := make(chan rune), make(chan error, 1)
+			go func() {
+				writeRunes(lfIn, text)
+				close(lfIn)
+				lfErr <- <-errIn
+				close(lfErr)
+			}()
+
+			lfOut, pErr := p(lfIn, lfErr)
+			term := []rune{'\n'}
+			for r := range lfOut {
+				if r != '\n' {
+					runeOut <- r
+					continue
+				}
+				if len(terms) > 0 {
+					term, terms = terms[0], terms[1:]
+				}
+				writeRunes(runeOut, term)
+			}
+			close(runeOut)
+			errOut <- <-pErr
+			close(errOut)
+		}()
+
+		return runeOut, errOut
+	}
+}
+
+// bom is the byte order mark.
+const bom = '\ufeff'
+
+// RemoveBOM removes the byte order mark at the start of the input.
+func RemoveBOM(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	runeOut := make(chan rune)
+
+	go func() {
+		first := true
+		for r := range runeIn {
+			if !first || r != bom {
+				runeOut <- r
+			}
+			first = false
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// EnsureBOMIfNonEmpty ensures non-empty content starts with
+// the byte order mark.
+func EnsureBOMIfNonEmpty(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	runeOut := make(chan rune)
+
+	go func() {
+		first := true
+		for r := range runeIn {
+			if first && r != bom {
+				runeOut <- bom
+			}
+			runeOut <- r
+			first = false
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
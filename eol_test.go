@@ -0,0 +1,72 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestConvertLineTerminatorsToCRLF(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":               {"", nil},
+		"a":              {"a", nil},
+		"a\nb\r\nc\rd\n": {"a\r\nb\r\nc\r\nd\r\n", nil},
+		"\r\r\n\n":       {"\r\n\r\n\r\n", nil},
+		"a\r\n\xff":      {"a\r\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.ConvertLineTerminatorsToCRLF,
+		testcases)
+}
+
+func TestConvertLineTerminatorsToCR(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":               {"", nil},
+		"a\nb\r\nc\rd\n": {"a\rb\rc\rd\r", nil},
+		"\n\xff":         {"\r", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.ConvertLineTerminatorsToCR,
+		testcases)
+}
+
+func TestKeepLineTerminators(t *testing.T) {
+	p := textproc.KeepLineTerminators(func(runeIn <-chan rune,
+		errIn <-chan error) (<-chan rune, <-chan error) {
+		return textproc.EnsureFinalLFIfNonEmpty(
+			textproc.TrimLFTrailingWhiteSpace(runeIn, errIn))
+	})
+	testcases := internal.RuneProcessorTestCases{
+		"":               {"", nil},
+		"a ":             {"a\n", nil},
+		"a \nb\r\nc ":    {"a\nb\r\nc\r\n", nil},
+		"a \r\nb\t\r\nc": {"a\r\nb\r\nc\r\n", nil},
+		"a \rb\n":        {"a\rb\n", nil},
+		"a \r":           {"a\r", nil},
+		"\r\r\n\n\r":     {"\r\r\n\n\r", nil},
+		"a\r\n\r\n\nb":   {"a\r\n\r\n\nb\n", nil},
+		"a \r\nb \xff":   {"a\r\nb", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, p, testcases)
+}
+
+func TestRemoveBOM(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                   {"", nil},
+		"\ufeff":             {"", nil},
+		"\ufeffa\ufeff":      {"a\ufeff", nil},
+		"a\ufeff":            {"a\ufeff", nil},
+		"\ufeff\ufeff\n\xff": {"\ufeff\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.RemoveBOM, testcases)
+}
+
+func TestEnsureBOMIfNonEmpty(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":           {"", nil},
+		"a":          {"\ufeffa", nil},
+		"\ufeffa":    {"\ufeffa", nil},
+		"\n\ufeff":   {"\ufeff\n\ufeff", nil},
+		"\xff":       {"", textproc.ErrInvalidUTF8},
+		"\ufeff\xff": {"\ufeff", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.EnsureBOMIfNonEmpty, testcases)
+}
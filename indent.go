@@ -0,0 +1,174 @@
+package textproc
+
+import "unicode"
+
+// indentColumns returns the length of the indentation of line,
+// its leading spaces and tabs, and its width in columns
+// with tab stops every tabWidth columns.
+func indentColumns(line []rune, tabWidth int) (length, columns int) {
+	for _, r := range line {
+		switch r {
+		case ' ':
+			columns++
+		case '\t':
+			columns += tabWidth - columns%tabWidth
+		default:
+			return
+		}
+		length++
+	}
+	return
+}
+
+// mapLFIndentation replaces the indentation of each line
+// with the output of indent for its width in columns.
+// Lines are terminated by "\n".
+func mapLFIndentation(tabWidth int,
+	indent func(columns int) []rune) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			length, columns := indentColumns(line, tabWidth)
+			return append(indent(columns), line[length:]...)
+		})
+	}
+}
+
+func repeatRune(r rune, n int) []rune {
+	runes := make([]rune, n)
+	for i := range runes {
+		runes[i] = r
+	}
+	return runes
+}
+
+// IndentLFWithSpaces converts the indentation of each line to spaces,
+// with tab stops every tabWidth columns.
+// Lines are terminated by "\n".
+func IndentLFWithSpaces(tabWidth int) RuneProcessor {
+	return mapLFIndentation(tabWidth, func(columns int) []rune {
+		return repeatRune(' ', columns)
+	})
+}
+
+// IndentLFWithTabs converts the indentation of each line to tabs,
+// with tab stops every tabWidth columns,
+// followed by the spaces which do not fill a tab.
+// Lines are terminated by "\n".
+func IndentLFWithTabs(tabWidth int) RuneProcessor {
+	return mapLFIndentation(tabWidth, func(columns int) []rune {
+		return append(repeatRune('\t', columns/tabWidth),
+			repeatRune(' ', columns%tabWidth)...)
+	})
+}
+
+// isBlank reports if line is empty or only contains white space.
+func isBlank(line []rune) bool {
+	for _, r := range line {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeIndentColumns removes columns from the indentation of line,
+// which must be at least that wide, with tab stops every tabWidth columns.
+// A tab crossing the boundary is replaced by the spaces after it.
+func removeIndentColumns(line []rune, columns, tabWidth int) []rune {
+	col := 0
+	for i, r := range line {
+		if col == columns {
+			return line[i:]
+		}
+		next := col + 1
+		if r == '\t' {
+			next = col + tabWidth - col%tabWidth
+		}
+		if next > columns {
+			return append(repeatRune(' ', next-columns), line[i+1:]...)
+		}
+		col = next
+	}
+	return nil
+}
+
+// dedent removes the indentation common to the lines of text
+// which are not blank, measured in columns, and empties the blank lines.
+func dedent(text []rune, tabWidth int) []rune {
+	lines := splitLF(text)
+	common := -1
+	for _, line := range lines {
+		if isBlank(line) {
+			continue
+		}
+		if _, columns := indentColumns(line, tabWidth); common == -1 ||
+			columns < common {
+			common = columns
+		}
+	}
+
+	var out []rune
+	for i, line := range lines {
+		if i > 0 {
+			out = append(out, '\n')
+		}
+		if !isBlank(line) {
+			out = append(out, removeIndentColumns(line, common, tabWidth)...)
+		}
+	}
+	return out
+}
+
+// splitLF splits text into lines terminated by "\n".
+// After a final "\n", the last line is empty.
+func splitLF(text []rune) [][]rune {
+	lines := [][]rune{nil}
+	for _, r := range text {
+		if r == '\n' {
+			lines = append(lines, nil)
+		} else {
+			lines[len(lines)-1] = append(lines[len(lines)-1], r)
+		}
+	}
+	return lines
+}
+
+// DedentLF removes the indentation common to all lines
+// which are not blank, like Python's textwrap.dedent,
+// and removes the white space of blank lines.
+// Indentation is measured in columns with tab stops every tabWidth columns,
+// so a tab and the spaces up to the same tab stop are the same indentation.
+// Lines are terminated by "\n".
+func DedentLF(tabWidth int) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapAll(runeIn, errIn, func(text []rune) []rune {
+			return dedent(text, tabWidth)
+		})
+	}
+}
+
+// DedentLFParagraphs applies DedentLF to each paragraph
+// (as read by ReadLFParagraphContent).
+// See LFParagraphs.Dedent for other paragraph separators.
+func DedentLFParagraphs(tabWidth int) RuneProcessor {
+	return LFParagraphs{}.Dedent(tabWidth)
+}
+
+// IndentLF adds prefix at the start of each line which is not blank,
+// like Python's textwrap.indent.
+// It keeps the empty lines between paragraphs unchanged,
+// so there is no per-paragraph variant.
+// Lines are terminated by "\n".
+func IndentLF(prefix string) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			if isBlank(line) {
+				return line
+			}
+			return append([]rune(prefix), line...)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestIndentLFWithSpaces(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                    {"", nil},
+		"a\tb\n":              {"a\tb\n", nil},
+		"\ta\n  \tb\n\t  c":   {"    a\n    b\n      c", nil},
+		"   \t\t\n":           {"        \n", nil},
+		" \t x \t\n\t\xff\tz": {"     x \t\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.IndentLFWithSpaces(4), testcases)
+}
+
+func TestIndentLFWithTabs(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                      {"", nil},
+		"a    b\n":              {"a    b\n", nil},
+		"    a\n      b\n  \tc": {"\ta\n\t  b\n\tc", nil},
+		"\t  \t   x  \n":        {"\t\t   x  \n", nil},
+		"        y\n    \xff  ": {"\t\ty\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.IndentLFWithTabs(4), testcases)
+}
+
+func TestDedentLF(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                            {"", nil},
+		"a\n  b\n":                    {"a\n  b\n", nil},
+		"    a\n      b\n\n  \n    c": {"a\n  b\n\n\nc", nil},
+		"\ta\n    \tb\n        c\n":   {"a\nb\nc\n", nil},
+		"\t\ta\n      b\n":            {"  \ta\nb\n", nil},
+		"  a\n \t\n":                  {"a\n\n", nil},
+		"  a\n\xff":                   {"", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.DedentLF(8), testcases)
+}
+
+func TestDedentLFParagraphs(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                             {"", nil},
+		"  a\n    b\n\n\n\tc\n\t  d\n": {"a\n  b\n\n\nc\n  d\n", nil},
+		"  x\n \n   y":                 {"x\n\n y", nil},
+		"  x\n\n  y\n\xff":             {"x\n\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.DedentLFParagraphs(4),
+		testcases)
+}
+
+func TestIndentLF(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":               {"", nil},
+		"a\n\n \n\tb\nc": {"> a\n\n \n> \tb\n> c", nil},
+		"a\n\n\nb\n\n":   {"> a\n\n\n> b\n\n", nil},
+		"a\nb\xff":       {"> a\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.IndentLF("> "), testcases)
+}
@@ -0,0 +1,202 @@
+package textproc
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrDuplicateKey is the error, wrapped in a LineError,
+// returned by SortINIKeysI for a key which appears again in its section.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// An iniEntry is a key or section header line
+// together with its continuation lines
+// and the comment and empty lines before it.
+type iniEntry struct {
+	lines [][]rune
+	key   []rune
+	// line of the key or section header, counting from 1.
+	line int
+}
+
+type iniSection struct {
+	// blank holds the empty lines before the header comment lines,
+	// which stay in place when the sections are sorted.
+	blank   [][]rune
+	header  *iniEntry
+	entries []*iniEntry
+}
+
+func isINIComment(trimmed string) bool {
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") ||
+		strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "!")
+}
+
+func isINIHeader(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")
+}
+
+// iniKey returns the key of a "key=value", "key: value", "key value"
+// or "export key=value" line.
+func iniKey(trimmed string) string {
+	trimmed = strings.TrimPrefix(trimmed, "export ")
+	if i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return r == '=' || r == ':' || unicode.IsSpace(r)
+	}); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	return trimmed
+}
+
+// continues reports if line ends with an odd number of backslashes
+// and so continues on the next line.
+func continues(line []rune) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func sortINIEntriesI(entries []*iniEntry) []*iniEntry {
+	keys := make([][]rune, len(entries))
+	for i := range entries {
+		keys[i] = entries[i].key
+	}
+	sorted := make([]*iniEntry, len(entries))
+	for i, j := range sortIndicesI(keys) {
+		sorted[i] = entries[j]
+	}
+	return sorted
+}
+
+// findDuplicateKey returns the first line whose key appears earlier
+// in the same section, compared case-insensitively like the sort, or 0.
+func findDuplicateKey(sections []*iniSection) int {
+	first := 0
+	for _, section := range sections {
+		seen := map[string]bool{}
+		for _, entry := range section.entries {
+			key := toLowerRunes(entry.key)
+			if seen[key] && (first == 0 || entry.line < first) {
+				first = entry.line
+			}
+			seen[key] = true
+		}
+	}
+	return first
+}
+
+// SortINIKeysI reads the content of all lines using ReadLFLineContent
+// and sorts the "key=value" lines of INI, properties and .env files
+// in case-insensitive order of the key, within each section.
+// The key ends at the first "=", ":" or white space
+// and can have an "export " prefix.
+//
+// Continuation lines (after a line ending in a backslash,
+// or indented right after a key line) and the comment and empty lines
+// before a key move together with the key.
+// Comment lines start with "#", ";" or "!".
+// Section headers like "[name]" stay in place, or, if sortSections is true,
+// the sections are sorted in case-insensitive order of their header
+// while the keys before the first header stay first
+// and the empty lines before each header's comment lines stay in place.
+// It adds "\n" after each line.
+//
+// After the output, it fails with a LineError wrapping ErrDuplicateKey
+// for the first key which appears again in its section,
+// ignoring case.
+func SortINIKeysI(sortSections bool) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		lineIn, errIn := ReadLFLineContent(runeIn, errIn)
+		runeOut, errOut := make(chan rune), make(chan error)
+
+		go func() {
+			sections := []*iniSection{{}}
+			var pending [][]rune
+			var last *iniEntry
+			lineNo, continued := 0, false
+
+			for line := range lineIn {
+				lineNo++
+				trimmed := strings.TrimSpace(string(line))
+				indented := len(line) > 0 && unicode.IsSpace(line[0])
+
+				switch {
+				case last != nil && (continued ||
+					(indented && !isINIComment(trimmed))):
+					last.lines = append(last.lines, line)
+				case isINIComment(trimmed):
+					pending = append(pending, line)
+					last = nil
+				case isINIHeader(trimmed):
+					n := 0
+					for n < len(pending) && isBlank(pending[n]) {
+						n++
+					}
+					sections = append(sections, &iniSection{
+						blank: pending[:n],
+						header: &iniEntry{append(pending[n:], line),
+							[]rune(trimmed), lineNo}})
+					pending, last = nil, nil
+				default:
+					section := sections[len(sections)-1]
+					last = &iniEntry{append(pending, line),
+						[]rune(iniKey(trimmed)), lineNo}
+					section.entries = append(section.entries, last)
+					pending = nil
+				}
+				continued = last != nil && continues(line)
+			}
+			blanks := make([][][]rune, len(sections))
+			for i, section := range sections {
+				section.entries = sortINIEntriesI(section.entries)
+				blanks[i] = section.blank
+			}
+			if sortSections {
+				headers := make([]*iniEntry, len(sections)-1)
+				bySection := map[*iniEntry]*iniSection{}
+				for i, section := range sections[1:] {
+					headers[i] = section.header
+					bySection[section.header] = section
+				}
+				for i, header := range sortINIEntriesI(headers) {
+					sections[i+1] = bySection[header]
+				}
+			}
+
+			writeLines := func(lines [][]rune) {
+				for _, line := range lines {
+					writeRunes(runeOut, line)
+					runeOut <- '\n'
+				}
+			}
+			for i, section := range sections {
+				writeLines(blanks[i])
+				entries := section.entries
+				if section.header != nil {
+					entries = append([]*iniEntry{section.header},
+						entries...)
+				}
+				for _, entry := range entries {
+					writeLines(entry.lines)
+				}
+			}
+			writeLines(pending)
+			close(runeOut)
+
+			err := <-errIn
+			if err == nil {
+				if line := findDuplicateKey(sections); line != 0 {
+					err = LineError{line, ErrDuplicateKey}
+				}
+			}
+			errOut <- err
+			close(errOut)
+		}()
+
+		return runeOut, errOut
+	}
+}
@@ -0,0 +1,37 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestSortINIKeysI(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                      {"", nil},
+		"b=1\nexport A=2\nc: 3": {"export A=2\nb=1\nc: 3\n", nil},
+		"[z]\nk=1\n; about j\nj=2\n\n[a]\ny = \\\n  two\nx=1\n": {
+			"[z]\n; about j\nj=2\nk=1\n\n[a]\nx=1\ny = \\\n  two\n", nil},
+		"[s]\nb=multi\n  line\na=1\n# end\n": {
+			"[s]\na=1\nb=multi\n  line\n# end\n", nil},
+		"b=1\\\\\nc=2\na=3\n": {"a=3\nb=1\\\\\nc=2\n", nil},
+		"k=1\n[s]\nk=2\nj=0\nk=3\nj=4\n": {"k=1\n[s]\nj=0\nj=4\nk=2\nk=3\n",
+			textproc.LineError{5, textproc.ErrDuplicateKey}},
+		"Key=1\na=0\nkey=2\n": {"a=0\nKey=1\nkey=2\n",
+			textproc.LineError{3, textproc.ErrDuplicateKey}},
+		"b=1\na=2\nc\xff\n": {"a=2\nb=1\n", textproc.ErrInvalidUTF8},
+		"b value\na: 1\nc\tx=y\nb2 = 2\n": {
+			"a: 1\nb value\nb2 = 2\nc\tx=y\n", nil},
+		"k v\nK=w\n": {"k v\nK=w\n",
+			textproc.LineError{2, textproc.ErrDuplicateKey}},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortINIKeysI(false), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"top=1\n[b]\nk=1\n\n# the a section\n[A]\nk=2\n": {
+			"top=1\n# the a section\n[A]\nk=2\n\n[b]\nk=1\n", nil},
+		"[c]\nk=1\n\n[b]\nk=2\n\n\n[a]\nk=3\n\n": {
+			"[a]\nk=3\n\n[b]\nk=2\n\n\n[c]\nk=1\n\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortINIKeysI(true), testcases)
+}
@@ -0,0 +1,224 @@
+package textproc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidCodePoint means a code point is not in the form U+XXXX.
+var ErrInvalidCodePoint = errors.New("invalid code point")
+
+// zwj is the zero width joiner, which joins emoji into one.
+const zwj = '\u200d'
+
+// otherInvisibleRunes are invisible runes outside category Cf.
+var otherInvisibleRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x034f, 0x034f, 1},
+		{0x115f, 0x1160, 1},
+		{0x3164, 0x3164, 1},
+		{0xffa0, 0xffa0, 1},
+	},
+}
+
+// emojiRunes are the emoji which zero width joiners combine.
+var emojiRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x2300, 0x23ff, 1},
+		{0x2600, 0x27bf, 1},
+		{0x2b00, 0x2bff, 1},
+		{0xfe0f, 0xfe0f, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f000, 0x1faff, 1},
+	},
+}
+
+// invisibleNames are the names of common invisible runes.
+var invisibleNames = map[rune]string{
+	0x00ad: "SOFT HYPHEN",
+	0x034f: "COMBINING GRAPHEME JOINER",
+	0x061c: "ARABIC LETTER MARK",
+	0x115f: "HANGUL CHOSEONG FILLER",
+	0x1160: "HANGUL JUNGSEONG FILLER",
+	0x180e: "MONGOLIAN VOWEL SEPARATOR",
+	0x200b: "ZERO WIDTH SPACE",
+	0x200c: "ZERO WIDTH NON-JOINER",
+	0x200d: "ZERO WIDTH JOINER",
+	0x200e: "LEFT-TO-RIGHT MARK",
+	0x200f: "RIGHT-TO-LEFT MARK",
+	0x202a: "LEFT-TO-RIGHT EMBEDDING",
+	0x202b: "RIGHT-TO-LEFT EMBEDDING",
+	0x202c: "POP DIRECTIONAL FORMATTING",
+	0x202d: "LEFT-TO-RIGHT OVERRIDE",
+	0x202e: "RIGHT-TO-LEFT OVERRIDE",
+	0x2060: "WORD JOINER",
+	0x2061: "FUNCTION APPLICATION",
+	0x2062: "INVISIBLE TIMES",
+	0x2063: "INVISIBLE SEPARATOR",
+	0x2064: "INVISIBLE PLUS",
+	0x2066: "LEFT-TO-RIGHT ISOLATE",
+	0x2067: "RIGHT-TO-LEFT ISOLATE",
+	0x2068: "FIRST STRONG ISOLATE",
+	0x2069: "POP DIRECTIONAL ISOLATE",
+	0x3164: "HANGUL FILLER",
+	0xfeff: "ZERO WIDTH NO-BREAK SPACE",
+	0xffa0: "HALFWIDTH HANGUL FILLER",
+}
+
+// IsInvisible reports if r is an invisible format character,
+// such as a zero width space, joiner or bidirectional control,
+// or another rune displayed as nothing, such as a Hangul filler.
+func IsInvisible(r rune) bool {
+	return unicode.In(r, unicode.Cf, otherInvisibleRunes)
+}
+
+// FormatCodePoint returns r as U+XXXX.
+func FormatCodePoint(r rune) string {
+	return fmt.Sprintf("U+%04X", r)
+}
+
+// ParseCodePoint parses a code point in the form U+XXXX or XXXX.
+func ParseCodePoint(s string) (rune, error) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(s, "U+"), "u+")
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return 0, fmt.Errorf("%w: %#v", ErrInvalidCodePoint, s)
+	}
+	return rune(n), nil
+}
+
+// An InvisibleAllowlist selects invisible runes which are kept.
+type InvisibleAllowlist struct {
+	// Runes are always kept.
+	Runes []rune
+	// EmojiZWJ keeps zero width joiners between two emoji,
+	// as in emoji sequences like "\U0001f469\u200d\U0001f4bb".
+	EmojiZWJ bool
+}
+
+// allows reports if r is always kept.
+// A nil allowlist allows nothing.
+func (a *InvisibleAllowlist) allows(r rune) bool {
+	if a == nil {
+		return false
+	}
+	for _, allowed := range a.Runes {
+		if r == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// scanInvisible calls emit for each rune, reporting if it is invisible
+// and not allowed by allow.
+// A byte order mark at the start of the input is not invisible.
+func scanInvisible(runeIn <-chan rune, allow *InvisibleAllowlist,
+	emit func(r rune, invisible bool)) {
+	// prev is the previous rune, or -1 at the start of the input.
+	prev := rune(-1)
+	pendingZWJ := false
+	for r := range runeIn {
+		if pendingZWJ {
+			emit(zwj, !unicode.Is(emojiRunes, r))
+			pendingZWJ = false
+		}
+
+		invisible := IsInvisible(r) && !allow.allows(r) &&
+			!(prev == -1 && r == bom)
+		if invisible && r == zwj && allow != nil && allow.EmojiZWJ &&
+			unicode.Is(emojiRunes, prev) {
+			pendingZWJ = true
+		} else {
+			emit(r, invisible)
+		}
+		prev = r
+	}
+	if pendingZWJ {
+		emit(zwj, true)
+	}
+}
+
+// An InvisibleRune is the position of an invisible rune.
+type InvisibleRune struct {
+	Position Position
+	Rune     rune
+}
+
+// String returns the position, the code point and, if known, the name.
+func (r InvisibleRune) String() string {
+	s := fmt.Sprint(r.Position, ": ", FormatCodePoint(r.Rune))
+	if name, ok := invisibleNames[r.Rune]; ok {
+		s += " " + name
+	}
+	return s
+}
+
+// FindInvisible reads all runes and returns the invisible ones
+// (see IsInvisible) not allowed by allow, which can be nil.
+// A byte order mark at the start of the input is not reported.
+func FindInvisible(runeIn <-chan rune, errIn <-chan error,
+	allow *InvisibleAllowlist) ([]InvisibleRune, error) {
+	var found []InvisibleRune
+	pos := Position{1, 1}
+	scanInvisible(runeIn, allow, func(r rune, invisible bool) {
+		if invisible {
+			found = append(found, InvisibleRune{pos, r})
+		}
+		if r == '\n' {
+			pos.Line, pos.Column = pos.Line+1, 1
+		} else {
+			pos.Column++
+		}
+	})
+	if err := <-errIn; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// mapInvisible replaces the invisible runes not allowed by allow
+// with the output of replace.
+func mapInvisible(allow *InvisibleAllowlist,
+	replace func(r rune) []rune) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		runeOut := make(chan rune)
+
+		go func() {
+			scanInvisible(runeIn, allow, func(r rune, invisible bool) {
+				if invisible {
+					writeRunes(runeOut, replace(r))
+				} else {
+					runeOut <- r
+				}
+			})
+			close(runeOut)
+		}()
+
+		return runeOut, errIn
+	}
+}
+
+// StripInvisible removes the invisible runes (see IsInvisible)
+// not allowed by allow, which can be nil,
+// except a byte order mark at the start of the input.
+func StripInvisible(allow *InvisibleAllowlist) RuneProcessor {
+	return mapInvisible(allow, func(rune) []rune {
+		return nil
+	})
+}
+
+// EscapeInvisible replaces the invisible runes (see IsInvisible)
+// not allowed by allow, which can be nil,
+// except a byte order mark at the start of the input,
+// with their code points as <U+XXXX>.
+func EscapeInvisible(allow *InvisibleAllowlist) RuneProcessor {
+	return mapInvisible(allow, func(r rune) []rune {
+		return []rune("<" + FormatCodePoint(r) + ">")
+	})
+}
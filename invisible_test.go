@@ -0,0 +1,100 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"strings"
+	"testing"
+)
+
+// emojiSeq is an emoji sequence joined by a zero width joiner.
+const emojiSeq = "\U0001f469\u200d\U0001f4bb"
+
+func TestIsInvisible(t *testing.T) {
+	for r, want := range map[rune]bool{
+		'a': false, ' ': false, '\t': false, '\u00a0': false,
+		'\u00ad': true, '\u200b': true, '\u200d': true, '\u202e': true,
+		'\u2066': true, '\ufeff': true, '\u3164': true, '\U000e0001': true,
+	} {
+		if got := textproc.IsInvisible(r); got != want {
+			t.Errorf("%U: want %v got %v", r, want, got)
+		}
+	}
+}
+
+func TestParseCodePoint(t *testing.T) {
+	for s, want := range map[string]rune{
+		"U+200D": '\u200d', "u+00ad": '\u00ad', "feff": '\ufeff',
+		"U+1F469": '\U0001f469',
+	} {
+		if got, err := textproc.ParseCodePoint(s); got != want || err != nil {
+			t.Errorf("%#v: want %U got %U %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"", "U+", "U+x", "+1", "110000", "U+-1"} {
+		if _, err := textproc.ParseCodePoint(s); err == nil {
+			t.Errorf("%#v: want error", s)
+		}
+	}
+	if got := textproc.FormatCodePoint('\u00ad'); got != "U+00AD" {
+		t.Errorf("Want %#v got %#v", "U+00AD", got)
+	}
+}
+
+func TestStripInvisible(t *testing.T) {
+	internal.CheckRuneProcessor(t, textproc.StripInvisible(nil),
+		internal.RuneProcessorTestCases{
+			"":                           {"", nil},
+			"\ufeffa\u200bb\ufeff":       {"\ufeffab", nil},
+			"if a\u202e {\u2066\n":       {"if a {\n", nil},
+			emojiSeq + "\n":              {"\U0001f469\U0001f4bb\n", nil},
+			"soft\u00adhyphen\u200d\xff": {"softhyphen", textproc.ErrInvalidUTF8},
+		})
+
+	allow := &textproc.InvisibleAllowlist{Runes: []rune{'\u00ad'},
+		EmojiZWJ: true}
+	internal.CheckRuneProcessor(t, textproc.StripInvisible(allow),
+		internal.RuneProcessorTestCases{
+			emojiSeq:                             {emojiSeq, nil},
+			"❤\ufe0f\u200d\U0001f525":            {"❤\ufe0f\u200d\U0001f525", nil},
+			"a\u200d\U0001f4bb \U0001f469\u200d": {"a\U0001f4bb \U0001f469", nil},
+			"\U0001f469\u200d\u200d\U0001f4bb":   {"\U0001f469\U0001f4bb", nil},
+			"soft\u00adhyphen\u200b":             {"soft\u00adhyphen", nil},
+		})
+}
+
+func TestEscapeInvisible(t *testing.T) {
+	internal.CheckRuneProcessor(t, textproc.EscapeInvisible(nil),
+		internal.RuneProcessorTestCases{
+			"a\u200bb\n\ufeff": {"a<U+200B>b\n<U+FEFF>", nil},
+			emojiSeq:           {"\U0001f469<U+200D>\U0001f4bb", nil},
+		})
+}
+
+func TestFindInvisible(t *testing.T) {
+	runeCh, errCh := textproc.ReadRunes(
+		strings.NewReader("\ufeffa\u200b\nb " + emojiSeq + "\u202e"))
+	found, err := textproc.FindInvisible(runeCh, errCh,
+		&textproc.InvisibleAllowlist{EmojiZWJ: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range found {
+		got = append(got, r.String())
+	}
+	want := "1:3: U+200B ZERO WIDTH SPACE|2:6: U+202E RIGHT-TO-LEFT OVERRIDE"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("Want %#v got %#v", want, strings.Join(got, "|"))
+	}
+
+	runeCh, errCh = textproc.ReadRunes(strings.NewReader("\u200b\xff"))
+	found, err = textproc.FindInvisible(runeCh, errCh, nil)
+	if err != textproc.ErrInvalidUTF8 || found != nil {
+		t.Fatal("Want", textproc.ErrInvalidUTF8, "got", found, err)
+	}
+	if got := (textproc.InvisibleRune{textproc.Position{1, 2},
+		'\U000e0001'}).String(); got != "1:2: U+E0001" {
+		t.Fatalf("Want %#v got %#v", "1:2: U+E0001", got)
+	}
+}
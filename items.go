@@ -0,0 +1,74 @@
+package textproc
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SortLFLineItemsI sorts the items of a delimited list in each line.
+//
+// If prefix is not nil, only lines matching prefix are changed
+// and the list is the text after the first match;
+// otherwise the list is the line after its leading white space.
+// Lines whose list, trimmed of white space, does not contain delim
+// are not changed.
+// The list is split on delim, the items are trimmed of white space,
+// empty items are removed, and if unique is true
+// only the first one of identical items is kept.
+// The items are sorted in case-insensitive order, like by SortLFLinesI,
+// and joined by delim with normalized spacing:
+// "a, b" and "a; b" for "," and ";", "a | b" for other delimiters
+// and "a b" if delim is only white space.
+// Lines are terminated by "\n".
+func SortLFLineItemsI(prefix *regexp.Regexp, delim string,
+	unique bool) RuneProcessor {
+	join := " "
+	switch trimmed := strings.TrimSpace(delim); trimmed {
+	case "":
+	case ",", ";":
+		join = trimmed + " "
+	default:
+		join = " " + trimmed + " "
+	}
+
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			text, list := "", string(line)
+			if prefix != nil {
+				loc := prefix.FindStringIndex(list)
+				if loc == nil {
+					return line
+				}
+				text, list = list[:loc[1]], list[loc[1]:]
+			} else {
+				list = strings.TrimLeftFunc(list, unicode.IsSpace)
+				text = string(line)[:len(string(line))-len(list)]
+			}
+			if !strings.Contains(strings.TrimSpace(list), delim) {
+				return line
+			}
+
+			var items [][]rune
+			seen := map[string]bool{}
+			for _, item := range strings.Split(list, delim) {
+				item = strings.TrimSpace(item)
+				if item == "" || (unique && seen[item]) {
+					continue
+				}
+				seen[item] = true
+				items = append(items, []rune(item))
+			}
+			sortTokensI(items)
+
+			for i, item := range items {
+				if i > 0 {
+					text += join
+				}
+				text += string(item)
+			}
+			return []rune(text)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"regexp"
+	"testing"
+)
+
+func TestSortLFLineItemsI(t *testing.T) {
+	prefix := regexp.MustCompile(`^\w+: *`)
+	testcases := internal.RuneProcessorTestCases{
+		"":                     {"", nil},
+		"imports: b, a,c":      {"imports: a, b, c", nil},
+		"Depends:zlib,libc6, ": {"Depends:libc6, zlib", nil},
+		"x,b,a\nk: B,a,b,a\n":  {"x,b,a\nk: a, a, B, b\n", nil},
+		"k: ":                  {"k: ", nil},
+		"k:  only  ":           {"k:  only  ", nil},
+		"k: b,a\nk: d,c\xff":   {"k: a, b\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.SortLFLineItemsI(prefix, ",", false), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"c  b a b\n\tz y": {"a b c\n\ty z", nil},
+		"  solo  \n\t\n":  {"  solo  \n\t\n", nil},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.SortLFLineItemsI(nil, " ", true), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"b | a | b | A":      {"a | A | b", nil},
+		"\t b|a\n  c , d \n": {"\t a | b\n  c , d \n", nil},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.SortLFLineItemsI(nil, "|", true), testcases)
+}
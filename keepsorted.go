@@ -0,0 +1,104 @@
+package textproc
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors reported by SortMarkedBlocks, wrapped in a LineError.
+var (
+	ErrUnmatchedStartMarker = errors.New("start marker without end marker")
+	ErrUnmatchedEndMarker   = errors.New("end marker without start marker")
+)
+
+// Default markers for SortMarkedBlocks.
+const (
+	KeepSortedStart = "keep-sorted start"
+	KeepSortedEnd   = "keep-sorted end"
+)
+
+// SortMarkedBlocks applies sort to each block of lines
+// between a line containing startMarker and a line containing endMarker.
+// The marker lines and the lines outside blocks are output unchanged.
+//
+// It fails with a LineError wrapping ErrUnmatchedStartMarker
+// for a start marker inside a block or without an end marker,
+// and ErrUnmatchedEndMarker for an end marker outside a block.
+// Lines are terminated by "\n".
+func SortMarkedBlocks(startMarker, endMarker string,
+	sort RuneProcessor) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		runeOut, errOut := make(chan rune), make(chan error)
+
+		go func() {
+			var line, block []rune
+			lineNo, blockStart := 0, 0
+
+			// processLine handles the content of a line
+			// followed by "\n" if terminated is true.
+			processLine := func(terminated bool) error {
+				lineNo++
+				if terminated {
+					line = append(line, '\n')
+				}
+				text := string(line)
+
+				switch {
+				case blockStart != 0 && strings.Contains(text, endMarker):
+					sorted, err := processRunes(sort, block)
+					if err != nil {
+						return err
+					}
+					writeRunes(runeOut, sorted)
+					writeRunes(runeOut, line)
+					block, blockStart = nil, 0
+				case strings.Contains(text, startMarker):
+					if blockStart != 0 {
+						return LineError{lineNo,
+							ErrUnmatchedStartMarker}
+					}
+					writeRunes(runeOut, line)
+					blockStart = lineNo
+				case blockStart != 0:
+					block = append(block, line...)
+				case strings.Contains(text, endMarker):
+					return LineError{lineNo, ErrUnmatchedEndMarker}
+				default:
+					writeRunes(runeOut, line)
+				}
+
+				line = nil
+				return nil
+			}
+
+			var err error
+			for r := range runeIn {
+				if r != '\n' {
+					line = append(line, r)
+					continue
+				}
+				if err = processLine(true); err != nil {
+					break
+				}
+			}
+
+			if err != nil {
+				drain(runeIn, errIn)
+			} else if err = <-errIn; err == nil {
+				if len(line) > 0 {
+					err = processLine(false)
+				}
+				if err == nil && blockStart != 0 {
+					err = LineError{blockStart,
+						ErrUnmatchedStartMarker}
+				}
+			}
+			close(runeOut)
+			errOut <- err
+			close(errOut)
+		}()
+
+		return runeOut, errOut
+	}
+}
@@ -0,0 +1,52 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestSortMarkedBlocks(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":       {"", nil},
+		"b\na  ": {"b\na  ", nil},
+		"z\n# keep-sorted start\nc\nB\na\n# keep-sorted end\ny\r\nx": {
+			"z\n# keep-sorted start\na\nB\nc\n# keep-sorted end\ny\r\nx",
+			nil},
+		"// keep-sorted start\n// keep-sorted end": {
+			"// keep-sorted start\n// keep-sorted end", nil},
+		"keep-sorted start\nb\na\nkeep-sorted end\n" +
+			"q\nkeep-sorted start\n2\n1\nkeep-sorted end\n": {
+			"keep-sorted start\na\nb\nkeep-sorted end\n" +
+				"q\nkeep-sorted start\n1\n2\nkeep-sorted end\n", nil},
+		"a\nkeep-sorted start\nb\na\n": {"a\nkeep-sorted start\n",
+			textproc.LineError{2, textproc.ErrUnmatchedStartMarker}},
+		"keep-sorted start\nkeep-sorted start\nkeep-sorted end\n": {
+			"keep-sorted start\n",
+			textproc.LineError{2, textproc.ErrUnmatchedStartMarker}},
+		"a\nb\nkeep-sorted end\nc\n": {"a\nb\n",
+			textproc.LineError{3, textproc.ErrUnmatchedEndMarker}},
+		"b\nkeep-sorted start\nd\nc\xff\n": {"b\nkeep-sorted start\n",
+			textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortMarkedBlocks(
+		textproc.KeepSortedStart, textproc.KeepSortedEnd,
+		textproc.SortLFLinesI), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"<<\nb\n1\n\n\na\n2\n>>\n": {"<<\na\n2\n\nb\n1\n>>\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortMarkedBlocks("<<", ">>",
+		textproc.SortLFParagraphsI), testcases)
+}
+
+func TestLineError(t *testing.T) {
+	err := textproc.LineError{7, textproc.ErrUnmatchedEndMarker}
+	want := "line 7: end marker without start marker"
+	if got := err.Error(); got != want {
+		t.Fatalf("Want %#v got %#v", want, got)
+	}
+	if got := err.Unwrap(); got != textproc.ErrUnmatchedEndMarker {
+		t.Fatal("Want", textproc.ErrUnmatchedEndMarker, "got", got)
+	}
+}
@@ -0,0 +1,246 @@
+package textproc
+
+import "regexp"
+
+// LFParagraphs configures where paragraphs end
+// for the paragraph processors and tokenizers.
+// Lines are terminated by "\n".
+//
+// Paragraphs are separated by separator lines:
+// empty lines, and the lines selected by the options.
+// The zero value only separates paragraphs on empty lines.
+type LFParagraphs struct {
+	// BlankLines makes lines of white space separators.
+	BlankLines bool
+	// Separator, if not nil, makes the lines it matches separators,
+	// such as "---" or a form feed.
+	Separator *regexp.Regexp
+	// DropSeparators replaces separator lines with empty lines
+	// in the output of the processors.
+	DropSeparators bool
+}
+
+// isSeparator reports if line separates paragraphs.
+func (p LFParagraphs) isSeparator(line []rune) bool {
+	return len(line) == 0 || p.BlankLines && isBlank(line) ||
+		p.Separator != nil && p.Separator.MatchString(string(line))
+}
+
+// outputSeparator returns the output of a separator line.
+func (p LFParagraphs) outputSeparator(line []rune) []rune {
+	if p.DropSeparators {
+		return nil
+	}
+	return line
+}
+
+// An lfBlock is a paragraph or a separator line.
+type lfBlock struct {
+	// content does not include the final line terminator.
+	content    []rune
+	separator  bool
+	terminated bool
+}
+
+// readBlocks reads the paragraphs and the separator lines.
+func (p LFParagraphs) readBlocks(runeIn <-chan rune, errIn <-chan error) (
+	<-chan *lfBlock, <-chan error) {
+	blockOut, errOut := make(chan *lfBlock), make(chan error)
+
+	go func() {
+		var par, line []rune
+
+		// endLine handles the line
+		// followed by "\n" if terminated is true.
+		endLine := func(terminated bool) {
+			if !p.isSeparator(line) {
+				if len(par) > 0 {
+					par = append(par, '\n')
+				}
+				par = append(par, line...)
+				if !terminated {
+					blockOut <- &lfBlock{content: par}
+				}
+				return
+			}
+
+			if len(par) != 0 {
+				blockOut <- &lfBlock{content: par, terminated: true}
+				par = nil
+			}
+			blockOut <- &lfBlock{content: line, separator: true,
+				terminated: terminated}
+		}
+
+		for r := range runeIn {
+			if r != '\n' {
+				line = append(line, r)
+				continue
+			}
+			endLine(true)
+			line = nil
+		}
+
+		err := <-errIn
+		if err == nil {
+			if len(line) != 0 {
+				endLine(false)
+			} else if len(par) != 0 {
+				blockOut <- &lfBlock{content: par, terminated: true}
+			}
+		}
+		close(blockOut)
+		errOut <- err
+		close(errOut)
+	}()
+
+	return blockOut, errOut
+}
+
+// ReadContent reads the content of each paragraph.
+// The content does not include the line terminator
+// of the paragraph's last line.
+// It is a Tokenizer.
+func (p LFParagraphs) ReadContent(runeIn <-chan rune, errIn <-chan error) (
+	<-chan []rune, <-chan error) {
+	blockIn, errIn := p.readBlocks(runeIn, errIn)
+	tokenOut := make(chan []rune)
+
+	go func() {
+		for block := range blockIn {
+			if !block.separator {
+				tokenOut <- block.content
+			}
+		}
+		close(tokenOut)
+	}()
+
+	return tokenOut, errIn
+}
+
+// ReadLFParagraphContent reads the content of each paragraph.
+// The content does not include the line terminator
+// of the paragraph's last line.
+//
+// A paragraph consists of adjacent non-empty lines.
+// Lines are terminated by "\n".
+// See LFParagraphs for other paragraph separators.
+func ReadLFParagraphContent(runeIn <-chan rune, errIn <-chan error) (
+	<-chan []rune, <-chan error) {
+	return LFParagraphs{}.ReadContent(runeIn, errIn)
+}
+
+// isEmptyGroup reports if the separator lines are all empty.
+func isEmptyGroup(group [][]rune) bool {
+	for _, line := range group {
+		if len(line) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SortI reads the content of all paragraphs,
+// sorts them in case-insensitive order and writes each one
+// followed by "\n".
+//
+// The separator lines between paragraphs stay in place.
+// Separator lines which are all empty are replaced by one empty line
+// between paragraphs and removed before the first paragraph
+// and after the last one.
+// It is a RuneProcessor.
+func (p LFParagraphs) SortI(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	blockIn, errIn := p.readBlocks(runeIn, errIn)
+	runeOut := make(chan rune)
+
+	go func() {
+		var paragraphs [][]rune
+		// groups[i] are the separator lines before paragraphs[i],
+		// and the last group those after the last paragraph.
+		groups := [][][]rune{nil}
+		for block := range blockIn {
+			if block.separator {
+				last := len(groups) - 1
+				groups[last] = append(groups[last],
+					p.outputSeparator(block.content))
+				continue
+			}
+			paragraphs = append(paragraphs, block.content)
+			groups = append(groups, nil)
+		}
+		sortTokensI(paragraphs)
+
+		for i, group := range groups {
+			switch {
+			case !isEmptyGroup(group):
+				for _, line := range group {
+					writeRunes(runeOut, line)
+					runeOut <- '\n'
+				}
+			case i > 0 && i < len(paragraphs):
+				runeOut <- '\n'
+			}
+			if i < len(paragraphs) {
+				writeRunes(runeOut, paragraphs[i])
+				runeOut <- '\n'
+			}
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// SortLFParagraphsI reads the content of all paragraphs
+// using ReadLFParagraphContent,
+// sorts the items in case-insensitive order, joins them with "\n\n"
+// and adds "\n" after the last one.
+// See LFParagraphs.SortI for other paragraph separators.
+func SortLFParagraphsI(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	return LFParagraphs{}.SortI(runeIn, errIn)
+}
+
+// mapContent applies fn to the content of each paragraph
+// keeping the separator lines, unless DropSeparators,
+// and the line terminators unchanged.
+func (p LFParagraphs) mapContent(runeIn <-chan rune, errIn <-chan error,
+	fn func(par []rune) []rune) (<-chan rune, <-chan error) {
+	blockIn, errIn := p.readBlocks(runeIn, errIn)
+	runeOut := make(chan rune)
+
+	go func() {
+		for block := range blockIn {
+			if block.separator {
+				writeRunes(runeOut, p.outputSeparator(block.content))
+			} else {
+				writeRunes(runeOut, fn(block.content))
+			}
+			if block.terminated {
+				runeOut <- '\n'
+			}
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// Substitute is SubstituteLFParagraphs with these paragraphs.
+func (p LFParagraphs) Substitute(subs ...*Substitution) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return p.mapContent(runeIn, errIn, substitute(subs))
+	}
+}
+
+// Dedent is DedentLFParagraphs with these paragraphs.
+func (p LFParagraphs) Dedent(tabWidth int) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return p.mapContent(runeIn, errIn, func(par []rune) []rune {
+			return dedent(par, tabWidth)
+		})
+	}
+}
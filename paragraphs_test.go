@@ -0,0 +1,85 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"regexp"
+	"testing"
+)
+
+func TestLFParagraphsReadContent(t *testing.T) {
+	p := textproc.LFParagraphs{BlankLines: true,
+		Separator: regexp.MustCompile(`^(---|\f)$`)}
+	testcases := internal.TokenizerTestCases{
+		"":                        {nil, nil},
+		"a\n \nb\n":               {[]string{"a", "b"}, nil},
+		"a\nb\n---\nc\n\f\n\t\nd": {[]string{"a\nb", "c", "d"}, nil},
+		"---\na\n--\nb\n---":      {[]string{"a\n--\nb"}, nil},
+		"a\n \nb\xff":             {[]string{"a"}, textproc.ErrInvalidUTF8},
+	}
+	internal.CheckTokenizer(t, p.ReadContent, testcases)
+
+	testcases = internal.TokenizerTestCases{
+		"a\n \nb\n": {[]string{"a\n \nb"}, nil},
+	}
+	internal.CheckTokenizer(t, textproc.LFParagraphs{}.ReadContent,
+		testcases)
+}
+
+func TestLFParagraphsSortI(t *testing.T) {
+	sep := regexp.MustCompile(`^---$`)
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{Separator: sep}.SortI,
+		internal.RuneProcessorTestCases{
+			"":                          {"", nil},
+			"c\n---\na\n---\nb":         {"a\n---\nb\n---\nc\n", nil},
+			"---\nc\n\n---\n\nb\n\n\na": {"---\na\n\n---\n\nb\n\nc\n", nil},
+			"b\n\na\n---\n":             {"a\n\nb\n---\n", nil},
+			"b\n---\na\xff":             {"b\n---\n", textproc.ErrInvalidUTF8},
+		})
+
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{Separator: sep, DropSeparators: true}.SortI,
+		internal.RuneProcessorTestCases{
+			"c\n---\na\n---\nb\n---\n": {"a\n\nb\n\nc\n", nil},
+		})
+
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{BlankLines: true}.SortI,
+		internal.RuneProcessorTestCases{
+			"b\n \na\n":  {"a\n \nb\n", nil},
+			"b\n\t\n\na": {"a\n\t\n\nb\n", nil},
+		})
+}
+
+func TestLFParagraphsSubstitute(t *testing.T) {
+	sub, err := textproc.ParseSubstitution(`s/\n/ /g`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sep := regexp.MustCompile(`^=+$`)
+
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{BlankLines: true, Separator: sep}.Substitute(
+			sub),
+		internal.RuneProcessorTestCases{
+			"a\nb\n==\nc\nd\n \ne\nf": {"a b\n==\nc d\n \ne f", nil},
+			"a\nb\n=":                 {"a b\n=", nil},
+		})
+
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{BlankLines: true, Separator: sep,
+			DropSeparators: true}.Substitute(sub),
+		internal.RuneProcessorTestCases{
+			"a\nb\n==\nc\nd\n \ne\nf\n": {"a b\n\nc d\n\ne f\n", nil},
+			"a\n===":                    {"a\n", nil},
+		})
+}
+
+func TestLFParagraphsDedent(t *testing.T) {
+	internal.CheckRuneProcessor(t,
+		textproc.LFParagraphs{Separator: regexp.MustCompile(`^#`)}.Dedent(8),
+		internal.RuneProcessorTestCases{
+			"  a\n    b\n# x\n\tc\n": {"a\n  b\n# x\nc\n", nil},
+		})
+}
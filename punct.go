@@ -0,0 +1,103 @@
+package textproc
+
+import "unicode"
+
+// asciiPunctuation are the ASCII equivalents of typographic punctuation.
+var asciiPunctuation = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`, '″': `"`, '‴': "'''",
+	// Hyphen, non-breaking hyphen, figure dash and en dash.
+	'\u2010': "-", '\u2011': "-", '\u2012': "-", '\u2013': "-",
+	// Em dash and horizontal bar.
+	'\u2014': "--", '\u2015': "--",
+	'…': "...",
+}
+
+// ConvertPunctuationToASCII replaces typographic quotes, primes,
+// dashes and the ellipsis with ASCII: ' " - -- ...
+func ConvertPunctuationToASCII(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	runeOut := make(chan rune)
+
+	go func() {
+		for r := range runeIn {
+			if ascii, ok := asciiPunctuation[r]; ok {
+				writeRunes(runeOut, []rune(ascii))
+			} else {
+				runeOut <- r
+			}
+		}
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
+
+// opensQuote reports if a quote after prev is an opening quote.
+// prev is 0 at the start of a line.
+func opensQuote(prev rune) bool {
+	return prev == 0 || unicode.IsSpace(prev) ||
+		unicode.In(prev, unicode.Ps, unicode.Pi, unicode.Pd)
+}
+
+// runLength returns the number of times line[i] repeats from i.
+func runLength(line []rune, i int) int {
+	n := 1
+	for i+n < len(line) && line[i+n] == line[i] {
+		n++
+	}
+	return n
+}
+
+// typographicLine returns line with typographic punctuation.
+func typographicLine(line []rune) []rune {
+	var out []rune
+	prev := rune(0)
+	for i := 0; i < len(line); i++ {
+		r := line[i]
+		var next rune
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+
+		switch {
+		case r == '"' && opensQuote(prev):
+			r = '“'
+		case r == '"':
+			r = '”'
+		case r == '\'' && opensQuote(prev) && !unicode.IsDigit(next):
+			r = '‘'
+		case r == '\'':
+			r = '’'
+		case r == '.' && runLength(line, i) == 3:
+			r = '…'
+			i += 2
+		case r == '-' && runLength(line, i) == 2 &&
+			!(opensQuote(prev) && i+2 < len(line) &&
+				!unicode.IsSpace(line[i+2])):
+			r = '—'
+			i++
+		case r == '.' || r == '-':
+			n := runLength(line, i)
+			out = append(out, line[i:i+n]...)
+			i += n - 1
+			prev = r
+			continue
+		}
+		out = append(out, r)
+		prev = r
+	}
+	return out
+}
+
+// ConvertPunctuationToTypographic replaces straight quotes
+// with opening and closing curly quotes, "--" with an em dash
+// and "..." with an ellipsis, like SmartyPants.
+// An apostrophe before a digit, as in '90s, is a closing quote.
+// Longer runs of dots and hyphens, and "--" starting a word
+// like a command line flag, are kept.
+// Lines are terminated by "\n".
+func ConvertPunctuationToTypographic(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	return mapLFLines(runeIn, errIn, typographicLine)
+}
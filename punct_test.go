@@ -0,0 +1,35 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestConvertPunctuationToASCII(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                      {"", nil},
+		"“Don’t” — ‘wait’…":     {`"Don't" -- 'wait'...`, nil},
+		"5′11″ 1–2 „x‟ ‚y‛ ―\n": {`5'11" 1-2 "x" 'y' --` + "\n", nil},
+		"non\u2011breaking\u2010hyphen \u2012 ‴\xff": {"non-breaking-hyphen - '''", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.ConvertPunctuationToASCII,
+		testcases)
+}
+
+func TestConvertPunctuationToTypographic(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"": {"", nil},
+		`"Don't," she said, "wait..."` + "\n": {
+			"“Don’t,” she said, “wait…”\n", nil},
+		`'quoted' ('paren') ["x"] 'n' '90s`: {
+			"‘quoted’ (‘paren’) [“x”] ‘n’ ’90s", nil},
+		"a -- b word--word --flag ---\n-- x": {
+			"a — b word—word --flag ---\n— x", nil},
+		"wait.... x.. a...b\n\xff": {
+			"wait.... x.. a…b\n", textproc.ErrInvalidUTF8},
+		"'\n\"": {"‘\n“", nil},
+	}
+	internal.CheckRuneProcessor(t,
+		textproc.ConvertPunctuationToTypographic, testcases)
+}
@@ -0,0 +1,485 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"regexp"
+)
+
+var (
+	commaParam = &Param{Name: "comma", Kind: RuneParam, Default: ",",
+		Doc: "CSV field separator"}
+	widthParam = &Param{Name: "width", Kind: IntParam, Default: "8",
+		Doc: "columns between tab stops"}
+	substParam = &Param{Name: "s", Kind: ListParam, Required: true,
+		Doc: "substitution s/regexp/replacement/flags (repeatable);" +
+			" flags: g all matches, i case-insensitive, l literal"}
+	startMarkerParam = &Param{Name: "start", Kind: StringParam,
+		Default: textproc.KeepSortedStart, Doc: "text of the line starting a block"}
+	endMarkerParam = &Param{Name: "end", Kind: StringParam,
+		Default: textproc.KeepSortedEnd, Doc: "text of the line ending a block"}
+	invisibleParams = []*Param{
+		{Name: "allow", Kind: ListParam,
+			Doc: "code point U+XXXX to keep (repeatable)"},
+		{Name: "emoji-zwj", Kind: BoolParam, Default: "true",
+			Doc: "keep zero width joiners between emoji"}}
+	paragraphParams = []*Param{
+		{Name: "blank", Kind: BoolParam,
+			Doc: "lines of white space also separate paragraphs"},
+		{Name: "sep", Kind: StringParam,
+			Doc: "regexp of lines which also separate paragraphs"},
+		{Name: "drop-sep", Kind: BoolParam,
+			Doc: "replace separator lines with empty lines"}}
+	showStyleParam = &Param{Name: "style", Kind: StringParam,
+		Default: "caret", Doc: "markers: caret (^I, $) or pictures (␉, ␊)"}
+)
+
+// fieldsParam declares the list of fields of a cut processor.
+func fieldsParam(fields string) *Param {
+	return &Param{Name: "fields", Kind: StringParam, Required: true,
+		Doc: "list of " + fields + " in output order, e.g. 3,1-2,5-"}
+}
+
+// substitutions parses the substitutions of the s parameter.
+func substitutions(p *Params) ([]*textproc.Substitution, error) {
+	var subs []*textproc.Substitution
+	for _, expr := range p.List("s") {
+		sub, err := textproc.ParseSubstitution(expr)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// newCut returns a cut processor splitting lines with split
+// and using defaultOutDelim if out-delim is not set.
+func newCut(p *Params, split textproc.FieldSplitter,
+	defaultOutDelim string) (textproc.RuneProcessor, error) {
+	ranges, err := textproc.ParseFieldRanges(p.Str("fields"))
+	if err != nil {
+		return nil, err
+	}
+	outDelim := defaultOutDelim
+	if p.IsSet("out-delim") {
+		outDelim = p.Str("out-delim")
+	}
+	return textproc.CutLFLines(split, ranges, outDelim), nil
+}
+
+// csvComma returns the CSV field separator parameter name,
+// which must not be a quote or a line terminator.
+func csvComma(p *Params, name string) (rune, error) {
+	switch r := p.Rune(name); r {
+	case '"', '\n', '\r':
+		return 0, errors.New(name +
+			" must not be a quote or a line terminator")
+	default:
+		return r, nil
+	}
+}
+
+// outputComma returns the out-comma parameter, or comma if it is not set.
+func outputComma(p *Params) (rune, error) {
+	if p.IsSet("out-comma") {
+		return csvComma(p, "out-comma")
+	}
+	return csvComma(p, "comma")
+}
+
+// sortMarkedBlocks returns a processor applying sort between
+// the start and end markers, which must not be empty.
+func sortMarkedBlocks(p *Params, sort textproc.RuneProcessor) (
+	textproc.RuneProcessor, error) {
+	for _, name := range []string{"start", "end"} {
+		if p.Str(name) == "" {
+			return nil, errors.New("empty " + name + " marker")
+		}
+	}
+	return textproc.SortMarkedBlocks(p.Str("start"), p.Str("end"), sort), nil
+}
+
+// tabStops returns the width parameter if it is valid.
+func tabStops(p *Params) (int, error) {
+	if width := p.Int("width"); width >= 1 {
+		return width, nil
+	}
+	return 0, errors.New("width must be at least 1")
+}
+
+// invisibleAllowlist returns the allowlist of the invisibleParams.
+func invisibleAllowlist(p *Params) (*textproc.InvisibleAllowlist, error) {
+	allow := &textproc.InvisibleAllowlist{EmojiZWJ: p.Bool("emoji-zwj")}
+	for _, s := range p.List("allow") {
+		r, err := textproc.ParseCodePoint(s)
+		if err != nil {
+			return nil, err
+		}
+		allow.Runes = append(allow.Runes, r)
+	}
+	return allow, nil
+}
+
+// paragraphs returns the paragraph separators of the paragraphParams.
+func paragraphs(p *Params) (textproc.LFParagraphs, error) {
+	pars := textproc.LFParagraphs{BlankLines: p.Bool("blank"),
+		DropSeparators: p.Bool("drop-sep")}
+	if p.Str("sep") != "" {
+		var err error
+		if pars.Separator, err = regexp.Compile(p.Str("sep")); err != nil {
+			return pars, err
+		}
+	}
+	return pars, nil
+}
+
+// showStyle returns the style parameter.
+func showStyle(p *Params) (textproc.ShowStyle, error) {
+	switch style := p.Str("style"); style {
+	case "caret":
+		return textproc.CaretNotation, nil
+	case "pictures":
+		return textproc.ControlPictures, nil
+	default:
+		return 0, fmt.Errorf("unknown style: %#v", style)
+	}
+}
+
+// normChain and strictNormChain are the specifications
+// of the norm and normstrict processors.
+const (
+	normChain       = "lf trail trimlf nelf"
+	strictNormChain = "norm squeeze"
+)
+
+var builtins = map[string]*Processor{
+	"asciipunct": {RuneProcessor: textproc.ConvertPunctuationToASCII,
+		Doc: "Replace typographic quotes, dashes and ellipses with ASCII"},
+	"bom": {RuneProcessor: textproc.EnsureBOMIfNonEmpty,
+		Doc: "Ensure non-empty content starts with a byte order mark"},
+	"cr": {RuneProcessor: textproc.ConvertLineTerminatorsToCR,
+		Doc: "Convert line terminators to CR"},
+	"crlf": {RuneProcessor: textproc.ConvertLineTerminatorsToCRLF,
+		Doc: "Convert line terminators to CRLF"},
+	"cut": {Doc: "Select fields of each line split on delim",
+		Params: []*Param{fieldsParam("fields"),
+			{Name: "delim", Kind: StringParam, Default: "\t",
+				Doc: "field delimiter"},
+			{Name: "regexp", Kind: BoolParam,
+				Doc: "delim is a regular expression"},
+			{Name: "out-delim", Kind: StringParam,
+				Doc: "output delimiter (default delim, or tab with regexp)"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			if !p.Bool("regexp") {
+				return newCut(p, textproc.SplitFieldsOn(p.Str("delim")),
+					p.Str("delim"))
+			}
+			re, err := regexp.Compile(p.Str("delim"))
+			if err != nil {
+				return nil, err
+			}
+			return newCut(p, textproc.SplitFieldsOnRegexp(re), "\t")
+		}},
+	"cutc": {Doc: "Select characters of each line",
+		Params: []*Param{fieldsParam("characters"),
+			{Name: "out-delim", Kind: StringParam, Doc: "output delimiter"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return newCut(p, textproc.SplitRunes, "")
+		}},
+	"cutd": {Doc: "Select display columns of each line",
+		Params: []*Param{fieldsParam("columns"),
+			{Name: "out-delim", Kind: StringParam, Doc: "output delimiter"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return newCut(p, textproc.SplitDisplayColumns, "")
+		}},
+	"csv2tsv": {Doc: "Convert CSV to TSV",
+		Params: []*Param{commaParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			comma, err := csvComma(p, "comma")
+			if err != nil {
+				return nil, err
+			}
+			return textproc.ConvertCSVToTSV(comma), nil
+		}},
+	"dedent": {Doc: "Remove the common indentation of the lines" +
+		" which are not blank (LF end of line)",
+		Params: append([]*Param{widthParam,
+			{Name: "paragraphs", Kind: BoolParam,
+				Doc: "dedent each paragraph separately" +
+					" (required by blank, sep and drop-sep)"}},
+			paragraphParams...),
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			width, err := tabStops(p)
+			if err != nil {
+				return nil, err
+			}
+			if !p.Bool("paragraphs") {
+				for _, decl := range paragraphParams {
+					if p.IsSet(decl.Name) {
+						return nil, errors.New(decl.Name +
+							" requires paragraphs")
+					}
+				}
+				return textproc.DedentLF(width), nil
+			}
+			pars, err := paragraphs(p)
+			if err != nil {
+				return nil, err
+			}
+			return pars.Dedent(width), nil
+		}},
+	"escinv": {Doc: "Replace invisible and bidirectional control" +
+		" characters with <U+XXXX>",
+		Params: invisibleParams,
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			allow, err := invisibleAllowlist(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.EscapeInvisible(allow), nil
+		}},
+	"indent": {Doc: "Prefix the lines which are not blank (LF end of line)",
+		Params: []*Param{{Name: "prefix", Kind: StringParam,
+			Default: "    ", Doc: "text added to each line"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return textproc.IndentLF(p.Str("prefix")), nil
+		}},
+	"keepsortli": {Doc: "Sort lines case-insensitive between markers",
+		Params: []*Param{startMarkerParam, endMarkerParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return sortMarkedBlocks(p, textproc.SortLFLinesI)
+		}},
+	"keepsortpi": {Doc: "Sort paragraphs case-insensitive between markers",
+		Params: append([]*Param{startMarkerParam, endMarkerParam},
+			paragraphParams...),
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			pars, err := paragraphs(p)
+			if err != nil {
+				return nil, err
+			}
+			return sortMarkedBlocks(p, pars.SortI)
+		}},
+	"lf": {RuneProcessor: textproc.ConvertLineTerminatorsToLF,
+		Doc: "Convert line terminators to LF"},
+	"nelf": {RuneProcessor: textproc.EnsureFinalLFIfNonEmpty,
+		Doc: "Ensure non-empty content ends with LF"},
+	"nobom": {RuneProcessor: textproc.RemoveBOM,
+		Doc: "Remove the byte order mark"},
+	"normcsv": {Doc: "Normalize CSV quoting",
+		Params: []*Param{commaParam,
+			{Name: "out-comma", Kind: RuneParam,
+				Doc: "output CSV field separator (default comma)"},
+			{Name: "quote-all", Kind: BoolParam, Doc: "quote all fields"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			comma, err := csvComma(p, "comma")
+			if err != nil {
+				return nil, err
+			}
+			outComma, err := outputComma(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.NormalizeCSV(comma, outComma,
+				p.Bool("quote-all")), nil
+		}},
+	"normspace": {Doc: "Replace other spaces than ASCII, like no-break," +
+		" em and ideographic spaces, with space (LF end of line)",
+		Params: []*Param{
+			{Name: "collapse", Kind: BoolParam, Doc: "replace each run" +
+				" of spaces after the indentation with one space"},
+			{Name: "keep-nbsp", Kind: BoolParam,
+				Doc: "keep the no-break spaces U+00A0, U+2007 and U+202F"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return textproc.NormalizeLFSpaces(textproc.SpaceOptions{
+				Collapse:    p.Bool("collapse"),
+				KeepNoBreak: p.Bool("keep-nbsp")}), nil
+		}},
+	"show": {Doc: "Make control characters, other spaces than ASCII," +
+		" invisible characters and line ends visible",
+		Params: []*Param{showStyleParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			style, err := showStyle(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.Show(style), nil
+		}},
+	"smartpunct": {RuneProcessor: textproc.ConvertPunctuationToTypographic,
+		Doc: "Replace straight quotes, -- and ... with typographic" +
+			" punctuation (LF end of line)"},
+	"sortcsv": {Doc: "Sort CSV records case-insensitive by column",
+		Params: []*Param{commaParam,
+			{Name: "column", Kind: IntParam, Default: "1",
+				Doc: "column to sort by, from 1"},
+			{Name: "header", Kind: BoolParam,
+				Doc: "keep the first record in place"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			if p.Int("column") < 1 {
+				return nil, errors.New("column must be at least 1")
+			}
+			comma, err := csvComma(p, "comma")
+			if err != nil {
+				return nil, err
+			}
+			return textproc.SortCSVRecordsI(comma, p.Int("column"),
+				p.Bool("header")), nil
+		}},
+	"sortini": {Doc: "Sort INI, properties and .env keys case-insensitive" +
+		" in each section",
+		Params: []*Param{{Name: "sections", Kind: BoolParam,
+			Doc: "also sort the sections"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			return textproc.SortINIKeysI(p.Bool("sections")), nil
+		}},
+	"sortitemsi": {Doc: "Sort the delimited items of each line" +
+		" case-insensitive",
+		Params: []*Param{
+			{Name: "delim", Kind: StringParam, Default: ",",
+				Doc: "item delimiter"},
+			{Name: "prefix", Kind: StringParam, Doc: "only change lines" +
+				" matching this regexp and sort the items after the match"},
+			{Name: "unique", Kind: BoolParam,
+				Doc: "remove duplicate items"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			if p.Str("delim") == "" {
+				return nil, errors.New("empty delim")
+			}
+			var prefix *regexp.Regexp
+			if p.Str("prefix") != "" {
+				var err error
+				if prefix, err = regexp.Compile(p.Str("prefix")); err != nil {
+					return nil, err
+				}
+			}
+			return textproc.SortLFLineItemsI(prefix, p.Str("delim"),
+				p.Bool("unique")), nil
+		}},
+	"sortlci": {Doc: "Sort lines case-insensitive between blank lines," +
+		" comment lines move with the next line",
+		Params: []*Param{{Name: "comment", Kind: StringParam, Default: "#",
+			Doc: "comment line prefix"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			if p.Str("comment") == "" {
+				return nil, errors.New("empty comment")
+			}
+			return textproc.SortLFLinesWithCommentsI(p.Str("comment")), nil
+		}},
+	"sortli": {RuneProcessor: textproc.SortLFLinesI,
+		Doc: "Sort lines case-insensitive (LF end of line)"},
+	"sortpi": {Doc: "Sort paragraphs case-insensitive (LF end of line)",
+		Params: paragraphParams,
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			pars, err := paragraphs(p)
+			if err != nil {
+				return nil, err
+			}
+			return pars.SortI, nil
+		}},
+	"sortti": {RuneProcessor: textproc.SortLFTreeI,
+		Doc: "Sort indentation tree case-insensitive (LF end of line)"},
+	"spaceindent": {Doc: "Indent with spaces (LF end of line)",
+		Params: []*Param{widthParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			width, err := tabStops(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.IndentLFWithSpaces(width), nil
+		}},
+	"squeeze": {Doc: "Squeeze runs of empty or white space lines" +
+		" (LF end of line)",
+		Params: []*Param{{Name: "max", Kind: IntParam, Default: "1",
+			Doc: "empty lines kept of each run"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			max := p.Int("max")
+			if max < 0 {
+				return nil, errors.New("max must not be negative")
+			}
+			return textproc.SqueezeEmptyLFLines(max), nil
+		}},
+	"stripinv": {Doc: "Remove invisible and bidirectional control" +
+		" characters",
+		Params: invisibleParams,
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			allow, err := invisibleAllowlist(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.StripInvisible(allow), nil
+		}},
+	"subst": {Doc: "Apply substitutions to each line",
+		Params: []*Param{substParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			subs, err := substitutions(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.SubstituteLFLines(subs...), nil
+		}},
+	"substp": {Doc: "Apply substitutions to each paragraph",
+		Params: append([]*Param{substParam}, paragraphParams...),
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			subs, err := substitutions(p)
+			if err != nil {
+				return nil, err
+			}
+			pars, err := paragraphs(p)
+			if err != nil {
+				return nil, err
+			}
+			return pars.Substitute(subs...), nil
+		}},
+	"tabindent": {Doc: "Indent with tabs (LF end of line)",
+		Params: []*Param{widthParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			width, err := tabStops(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.IndentLFWithTabs(width), nil
+		}},
+	"trail": {RuneProcessor: textproc.TrimLFTrailingWhiteSpace,
+		Doc: "Remove trailing whitespace (LF end of line)"},
+	"tsv2csv": {Doc: "Convert TSV to CSV",
+		Params: []*Param{{Name: "out-comma", Kind: RuneParam, Default: ",",
+			Doc: "output CSV field separator"}},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			comma, err := csvComma(p, "out-comma")
+			if err != nil {
+				return nil, err
+			}
+			return textproc.ConvertTSVToCSV(comma), nil
+		}},
+	"trimlf": {RuneProcessor: Chain(textproc.TrimLeadingEmptyLFLines,
+		textproc.TrimTrailingEmptyLFLines),
+		Doc: "Trim leading and trailing empty lines (LF end of line)"},
+	"unshow": {Doc: "Reverse show",
+		Params: []*Param{showStyleParam},
+		New: func(p *Params) (textproc.RuneProcessor, error) {
+			style, err := showStyle(p)
+			if err != nil {
+				return nil, err
+			}
+			return textproc.Unshow(style), nil
+		}},
+}
+
+// Builtin returns a new registry with the built-in processors.
+// More processors can be registered in it.
+func Builtin() *Registry {
+	r := New()
+	for name, p := range builtins {
+		r.MustRegister(name, p)
+	}
+	if err := r.RegisterChain("norm", "Normalize: "+normChain,
+		normChain); err != nil {
+		panic(err)
+	}
+	if err := r.RegisterChain("normstrict", "Normalize and squeeze empty"+
+		" lines: "+strictNormChain, strictNormChain); err != nil {
+		panic(err)
+	}
+	return r
+}
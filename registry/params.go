@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"strconv"
+)
+
+// A ParamKind is the type of a processor parameter.
+type ParamKind int
+
+// Parameter kinds.
+const (
+	StringParam ParamKind = iota
+	IntParam
+	BoolParam
+	RuneParam
+	// ListParam collects the values of a repeated parameter.
+	ListParam
+)
+
+func (k ParamKind) String() string {
+	return [...]string{"string", "int", "bool", "char", "string…"}[k]
+}
+
+// A Param declares a parameter of a processor.
+type Param struct {
+	Name string
+	Kind ParamKind
+	// Default is the default value in the syntax of the parameter values.
+	// An empty Default means the zero value
+	// (no character for a RuneParam).
+	Default string
+	// Required parameters have no default and must be given.
+	Required bool
+	Doc      string
+}
+
+// parseValue parses the value of the parameter.
+func (p *Param) parseValue(value string) (interface{}, error) {
+	switch p.Kind {
+	case IntParam:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("not an integer: %#v", value)
+		}
+		return n, nil
+	case BoolParam:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a boolean: %#v", value)
+		}
+		return b, nil
+	case RuneParam:
+		runes := []rune(value)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("not a single character: %#v", value)
+		}
+		return runes[0], nil
+	case ListParam:
+		return []string{value}, nil
+	default:
+		return value, nil
+	}
+}
+
+// Params holds the parameter values of a processor.
+// Its methods panic if the parameter is not declared with their kind.
+type Params struct {
+	values map[string]interface{}
+	// set records the parameters which were given.
+	set map[string]bool
+}
+
+// Str returns the value of a StringParam.
+func (p *Params) Str(name string) string {
+	return p.values[name].(string)
+}
+
+// Int returns the value of an IntParam.
+func (p *Params) Int(name string) int {
+	return p.values[name].(int)
+}
+
+// Bool returns the value of a BoolParam.
+func (p *Params) Bool(name string) bool {
+	return p.values[name].(bool)
+}
+
+// Rune returns the value of a RuneParam, or 0 if it has no value.
+func (p *Params) Rune(name string) rune {
+	r, _ := p.values[name].(rune)
+	return r
+}
+
+// List returns the values of a ListParam.
+func (p *Params) List(name string) []string {
+	list, _ := p.values[name].([]string)
+	return list
+}
+
+// IsSet reports if the parameter was given.
+func (p *Params) IsSet(name string) bool {
+	return p.set[name]
+}
+
+// ParseParams parses the comma-separated "name=value" parameters
+// in text, in the syntax of ParsePipeline, using the declarations decls.
+// A boolean parameter without "=value" is true.
+// Syntax errors are reported as SpecError.
+func ParseParams(decls []*Param, text string) (*Params, error) {
+	var args []*Arg
+	if text != "" {
+		parser := &pipelineParser{spec: text,
+			pos: textproc.Position{Line: 1, Column: 1}}
+		var err error
+		if args, err = parser.args(); err != nil {
+			return nil, err
+		}
+		if !parser.done() {
+			return nil, SpecError{parser.pos, ErrAfterParams}
+		}
+	}
+	p, _, err := bindParams(decls, args)
+	return p, err
+}
+
+// bindParams returns the values of the parameters decls given by args.
+// If an argument is invalid, it also returns that argument.
+func bindParams(decls []*Param, args []*Arg) (*Params, *Arg, error) {
+	p := &Params{values: map[string]interface{}{}, set: map[string]bool{}}
+	byName := map[string]*Param{}
+	for _, decl := range decls {
+		byName[decl.Name] = decl
+		switch {
+		case decl.Default != "":
+			value, err := decl.parseValue(decl.Default)
+			if err != nil {
+				panic(fmt.Sprintf("default of %s: %v", decl.Name, err))
+			}
+			p.values[decl.Name] = value
+		case decl.Kind == StringParam:
+			p.values[decl.Name] = ""
+		case decl.Kind == IntParam:
+			p.values[decl.Name] = 0
+		case decl.Kind == BoolParam:
+			p.values[decl.Name] = false
+		}
+	}
+
+	for _, arg := range args {
+		name, value := arg.Name, arg.Value
+		decl, ok := byName[name]
+		switch {
+		case !ok:
+			return nil, arg, fmt.Errorf("unknown parameter: %#v", name)
+		case p.set[name] && decl.Kind != ListParam:
+			return nil, arg, errors.New("duplicate parameter: " + name)
+		case !arg.HasValue && decl.Kind != BoolParam:
+			return nil, arg, errors.New(name + ": missing value")
+		case !arg.HasValue:
+			value = "true"
+		}
+
+		parsed, err := decl.parseValue(value)
+		if err != nil {
+			return nil, arg, fmt.Errorf("%s: %w", name, err)
+		}
+		if decl.Kind == ListParam {
+			parsed = append(p.List(name), parsed.([]string)...)
+		}
+		p.values[name], p.set[name] = parsed, true
+	}
+
+	for _, decl := range decls {
+		if decl.Required && !p.set[decl.Name] {
+			return nil, nil, errors.New("missing parameter: " + decl.Name)
+		}
+	}
+	return p, nil, nil
+}
+
+// Usage returns the documentation of the parameter.
+func (p *Param) Usage() string {
+	doc := fmt.Sprint(p.Name, "=", p.Kind, "\t", p.Doc)
+	switch {
+	case p.Required:
+		doc += " (required)"
+	case p.Default != "":
+		doc += fmt.Sprintf(" (default %q)", p.Default)
+	}
+	return doc
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	decls := []*Param{
+		{Name: "s", Kind: StringParam, Default: "x"},
+		{Name: "n", Kind: IntParam, Default: "2"},
+		{Name: "b", Kind: BoolParam},
+		{Name: "r", Kind: RuneParam},
+		{Name: "l", Kind: ListParam},
+	}
+
+	p, err := ParseParams(decls, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Str("s") != "x" || p.Int("n") != 2 || p.Bool("b") ||
+		p.Rune("r") != 0 || p.List("l") != nil || len(p.set) != 0 {
+		t.Fatal("Unexpected", p.values)
+	}
+
+	p, err = ParseParams(decls, `l=1,s=",",n=-3,b,r=→,l=2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Str("s") != "," || p.Int("n") != -3 || !p.Bool("b") ||
+		p.Rune("r") != '→' || strings.Join(p.List("l"), " ") != "1 2" ||
+		!p.IsSet("s") {
+		t.Fatal("Unexpected", p.values)
+	}
+
+	required := append(decls, &Param{Name: "q", Kind: StringParam,
+		Required: true})
+	for text, want := range map[string]string{
+		"x":       `unknown parameter: "x"`,
+		"=1":      "1:1: " + ErrMissingParamName.Error(),
+		"x=1 y":   "1:4: " + ErrAfterParams.Error(),
+		`s="a`:    "1:3: " + ErrUnterminatedQuote.Error(),
+		"n":       "n: missing value",
+		"n=1,n=2": "duplicate parameter: n",
+		"b=yes":   `b: not a boolean: "yes"`,
+		"r=":      `r: not a single character: ""`,
+		"s=a":     "missing parameter: q",
+	} {
+		if p, err := ParseParams(required, text); err == nil ||
+			err.Error() != want || p != nil {
+			t.Errorf("%#v: want %#v got %v %v", text, want, p, err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Errors of ParsePipeline and ParseParams.
+var (
+	ErrMissingProcessorName = errors.New("missing processor name")
+	ErrMissingParamName     = errors.New("missing parameter name")
+	ErrUnterminatedQuote    = errors.New("unterminated quoted value")
+	ErrAfterQuote           = errors.New(
+		"want comma or white space after quoted value")
+	ErrAfterParams = errors.New("want end of parameters")
+)
+
+// A SpecError is an error at a position of a pipeline specification.
+type SpecError struct {
+	Position textproc.Position
+	Err      error
+}
+
+func (e SpecError) Error() string {
+	return fmt.Sprint(e.Position, ": ", e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e SpecError) Unwrap() error {
+	return e.Err
+}
+
+// An Arg is a parameter given to a processor.
+type Arg struct {
+	Name, Value string
+	// HasValue is false for a boolean parameter given without "=value".
+	HasValue bool
+	// Position is the position of Name in the specification.
+	Position textproc.Position
+}
+
+func (a *Arg) String() string {
+	if !a.HasValue {
+		return a.Name
+	}
+	return a.Name + "=" + quoteValue(a.Value)
+}
+
+// quoteValue returns value in the syntax of a pipeline specification.
+func quoteValue(value string) string {
+	if strings.ContainsAny(value, "\",") || strings.HasSuffix(value, `\`) ||
+		strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// A Step is a processor of a pipeline.
+type Step struct {
+	Name string
+	Args []*Arg
+	// Position is the position of Name in the specification.
+	Position textproc.Position
+}
+
+func (s *Step) String() string {
+	if len(s.Args) == 0 {
+		return s.Name
+	}
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		args[i] = arg.String()
+	}
+	return s.Name + ":" + strings.Join(args, ",")
+}
+
+// A Pipeline is a parsed pipeline specification.
+type Pipeline struct {
+	Steps []*Step
+}
+
+// String returns the specification of the pipeline.
+// Parsing it returns the same steps and arguments.
+func (p *Pipeline) String() string {
+	steps := make([]string, len(p.Steps))
+	for i, step := range p.Steps {
+		steps[i] = step.String()
+	}
+	return strings.Join(steps, " ")
+}
+
+// pipelineParser holds the state of ParsePipeline.
+type pipelineParser struct {
+	spec string
+	// i is the offset of the next byte
+	// and pos is its position.
+	i   int
+	pos textproc.Position
+}
+
+func (p *pipelineParser) done() bool {
+	return p.i == len(p.spec)
+}
+
+func (p *pipelineParser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.spec[p.i:])
+	return r
+}
+
+func (p *pipelineParser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.spec[p.i:])
+	p.i += size
+	if r == '\n' {
+		p.pos.Line, p.pos.Column = p.pos.Line+1, 1
+	} else {
+		p.pos.Column++
+	}
+	return r
+}
+
+// atSeparator reports if the parser is at the end of a step.
+func (p *pipelineParser) atSeparator() bool {
+	return p.done() || unicode.IsSpace(p.peek())
+}
+
+// until reads up to the end of the step or one of the delimiter runes.
+func (p *pipelineParser) until(delims string) string {
+	start := p.i
+	for !p.atSeparator() && !strings.ContainsRune(delims, p.peek()) {
+		p.next()
+	}
+	return p.spec[start:p.i]
+}
+
+// value reads an unquoted value, where "\," escapes a comma,
+// or a quoted value in Go syntax.
+func (p *pipelineParser) value() (string, error) {
+	pos := p.pos
+	if p.done() || p.peek() != '"' {
+		var value strings.Builder
+		for !p.atSeparator() && p.peek() != ',' {
+			if r := p.next(); r == '\\' && !p.done() && p.peek() == ',' {
+				value.WriteRune(p.next())
+			} else {
+				value.WriteRune(r)
+			}
+		}
+		return value.String(), nil
+	}
+
+	start := p.i
+	p.next()
+	for !p.done() && p.peek() != '"' && p.peek() != '\n' {
+		if p.next() == '\\' && !p.done() {
+			p.next()
+		}
+	}
+	if p.done() || p.peek() != '"' {
+		return "", SpecError{pos, ErrUnterminatedQuote}
+	}
+	p.next()
+	value, err := strconv.Unquote(p.spec[start:p.i])
+	if err != nil {
+		return "", SpecError{pos, fmt.Errorf("invalid quoted value: %w", err)}
+	}
+	if !p.atSeparator() && p.peek() != ',' {
+		return "", SpecError{p.pos, ErrAfterQuote}
+	}
+	return value, nil
+}
+
+func (p *pipelineParser) step() (*Step, error) {
+	step := &Step{Position: p.pos}
+	if step.Name = p.until(":"); step.Name == "" {
+		return nil, SpecError{step.Position, ErrMissingProcessorName}
+	}
+	if !nameRegexp.MatchString(step.Name) {
+		return nil, SpecError{step.Position,
+			fmt.Errorf("invalid processor name: %#v", step.Name)}
+	}
+	if p.atSeparator() {
+		return step, nil
+	}
+
+	p.next()
+	var err error
+	if step.Args, err = p.args(); err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+// args reads the comma-separated arguments up to the end of the step.
+func (p *pipelineParser) args() ([]*Arg, error) {
+	var args []*Arg
+	for {
+		arg := &Arg{Position: p.pos}
+		if arg.Name = p.until("=,"); arg.Name == "" {
+			return nil, SpecError{arg.Position, ErrMissingParamName}
+		}
+		if !p.done() && p.peek() == '=' {
+			p.next()
+			var err error
+			if arg.Value, err = p.value(); err != nil {
+				return nil, err
+			}
+			arg.HasValue = true
+		}
+		args = append(args, arg)
+
+		if p.atSeparator() {
+			return args, nil
+		}
+		p.next()
+	}
+}
+
+// ParsePipeline parses a pipeline specification:
+// processors separated by white space.
+//
+// A processor is a name, optionally followed by a colon and
+// comma-separated parameters "name=value" or "name" (a true boolean).
+// A value is either unquoted, ending at a comma or white space,
+// where "\," is a literal comma, or double-quoted in Go syntax.
+//
+// Errors are reported as SpecError.
+func ParsePipeline(spec string) (*Pipeline, error) {
+	p := &pipelineParser{spec: spec, pos: textproc.Position{Line: 1, Column: 1}}
+	pipeline := &Pipeline{}
+	for {
+		for !p.done() && unicode.IsSpace(p.peek()) {
+			p.next()
+		}
+		if p.done() {
+			return pipeline, nil
+		}
+		step, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Steps = append(pipeline.Steps, step)
+	}
+}
+
+// BuildPipeline returns the chain of the processors of pipeline.
+// Errors are reported as SpecError at the position
+// of the step or argument causing them.
+func (r *Registry) BuildPipeline(pipeline *Pipeline) (
+	textproc.RuneProcessor, error) {
+	var runeProcs []textproc.RuneProcessor
+	for _, step := range pipeline.Steps {
+		runeProc, arg, err := r.buildStep(step)
+		if err != nil {
+			pos := step.Position
+			if arg != nil {
+				pos = arg.Position
+			}
+			return nil, SpecError{pos, err}
+		}
+		runeProcs = append(runeProcs, runeProc)
+	}
+	return Chain(runeProcs...), nil
+}
+
+// Compile parses the pipeline specification spec
+// and returns the chain of its processors.
+// Errors are reported as SpecError.
+func (r *Registry) Compile(spec string) (textproc.RuneProcessor, error) {
+	pipeline, err := ParsePipeline(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.BuildPipeline(pipeline)
+}
+
+// RegisterChain registers under name the chain of the processors
+// specified by spec, which may use other chains.
+// If doc is empty, the processor is documented by spec.
+func (r *Registry) RegisterChain(name, doc, spec string) error {
+	pipeline, err := ParsePipeline(spec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	runeProc, err := r.BuildPipeline(pipeline)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if doc == "" {
+		doc = "Chain: " + pipeline.String()
+	}
+	return r.Register(name, &Processor{Doc: doc, RuneProcessor: runeProc})
+}
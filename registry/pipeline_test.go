@@ -0,0 +1,153 @@
+package registry_test
+
+import (
+	"errors"
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"github.com/MihaiB/textproc/v3/registry"
+	"testing"
+)
+
+func TestParsePipeline(t *testing.T) {
+	for spec, want := range map[string]string{
+		"":                          "",
+		" \n\t":                     "",
+		"lf  trail\n nelf":          "lf trail nelf",
+		"cut:fields=2\\,1,delim=;":  `cut:fields="2,1",delim=;`,
+		`subst:s="a b",s="\t"`:      `subst:s="a b",s="\t"`,
+		`subst:s=a\b,s=a\`:          `subst:s=a\b,s="a\\"`,
+		"sortitemsi:unique,prefix=": "sortitemsi:unique,prefix=",
+		`subst:s="",s=é`:            `subst:s=,s=é`,
+	} {
+		p, err := registry.ParsePipeline(spec)
+		if err != nil {
+			t.Errorf("%#v: %v", spec, err)
+			continue
+		}
+		if got := p.String(); got != want {
+			t.Errorf("%#v: want %#v got %#v", spec, want, got)
+			continue
+		}
+		again, err := registry.ParsePipeline(want)
+		if err != nil {
+			t.Errorf("%#v: %v", want, err)
+		} else if got := again.String(); got != want {
+			t.Errorf("%#v: round trip got %#v", want, got)
+		}
+	}
+}
+
+func TestParsePipelineArgs(t *testing.T) {
+	p, err := registry.ParsePipeline("lf\n  subst:s=\"x\\\\,y\",b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Steps) != 2 {
+		t.Fatal("Want 2 steps, got", len(p.Steps))
+	}
+	step := p.Steps[1]
+	if step.Name != "subst" ||
+		step.Position != (textproc.Position{Line: 2, Column: 3}) ||
+		len(step.Args) != 2 {
+		t.Fatalf("Wrong step %#v", step)
+	}
+	if want := (registry.Arg{Name: "s", Value: `x\,y`, HasValue: true,
+		Position: textproc.Position{Line: 2, Column: 9}}); *step.Args[0] != want {
+		t.Errorf("Want %#v got %#v", want, *step.Args[0])
+	}
+	if want := (registry.Arg{Name: "b",
+		Position: textproc.Position{Line: 2, Column: 19}}); *step.Args[1] != want {
+		t.Errorf("Want %#v got %#v", want, *step.Args[1])
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"lf :x":            "1:4: " + registry.ErrMissingProcessorName.Error(),
+		"lf\n Norm":        `2:2: invalid processor name: "Norm"`,
+		"cut:":             "1:5: " + registry.ErrMissingParamName.Error(),
+		"cut:fields=1,":    "1:14: " + registry.ErrMissingParamName.Error(),
+		"cut:=1":           "1:5: " + registry.ErrMissingParamName.Error(),
+		`subst:s="a`:       "1:9: " + registry.ErrUnterminatedQuote.Error(),
+		"subst:s=\"a\n\"":  "1:9: " + registry.ErrUnterminatedQuote.Error(),
+		`subst:s="a"b`:     "1:12: " + registry.ErrAfterQuote.Error(),
+		`subst:s=é,s="\q"`: "1:13: invalid quoted value: invalid syntax",
+	} {
+		p, err := registry.ParsePipeline(spec)
+		if err == nil || err.Error() != want || p != nil {
+			t.Errorf("%#v: want %#v got %v", spec, want, err)
+		}
+		if !errors.As(err, &registry.SpecError{}) {
+			t.Errorf("%#v: want SpecError got %T", spec, err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	r := registry.Builtin()
+	for spec, want := range map[string]string{
+		"lf nope":                "1:4: unknown processor: nope",
+		"lf\ncut":                "2:1: cut: missing parameter: fields",
+		"lf cut:fields=1,x=2":    `1:17: cut: unknown parameter: "x"`,
+		"cut:fields=1,fields=2":  "1:14: cut: duplicate parameter: fields",
+		"tabindent:width=0":      "1:1: tabindent: width must be at least 1",
+		"sortcsv:column=x":       `1:9: sortcsv: column: not an integer: "x"`,
+		"subst:s=x":              "1:1: subst: " + textproc.ErrInvalidSubstitution.Error(),
+		"lf :x":                  "1:4: " + registry.ErrMissingProcessorName.Error(),
+		"sortitemsi:unique=yes ": `1:12: sortitemsi: unique: not a boolean: "yes"`,
+	} {
+		p, err := r.Compile(spec)
+		if err == nil || err.Error() != want || p != nil {
+			t.Errorf("%#v: want %#v got %v", spec, want, err)
+		}
+	}
+
+	p, err := r.Compile(`norm cut:fields=2\,1,delim=";" sortli`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"a;b \r\nc;d\n\n": {"b;a\nd;c\n", nil},
+	})
+}
+
+func TestRegisterChain(t *testing.T) {
+	builtin := registry.Builtin()
+	r := builtin.Clone()
+	if err := r.RegisterChain("clean", "", "norm  sortli"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterChain("twice", "Clean twice", "clean clean"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"clean": "Chain: norm sortli",
+		"twice": "Clean twice",
+	} {
+		if p, ok := r.Lookup(name); !ok || p.Doc != want {
+			t.Errorf("%v: want doc %#v", name, want)
+		}
+	}
+	if _, ok := builtin.Lookup("clean"); ok {
+		t.Error("Clone registered into the original registry")
+	}
+
+	for name, spec := range map[string]string{
+		"clean":  "lf",
+		"broken": "lf nope",
+		"bad":    "lf :",
+		"Bad":    "lf",
+	} {
+		if err := r.RegisterChain(name, "", spec); err == nil {
+			t.Errorf("%v %#v: want error", name, spec)
+		}
+	}
+
+	p, err := r.Compile("twice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"b\r\na \n\n": {"a\nb\n", nil},
+	})
+}
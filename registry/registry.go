@@ -0,0 +1,179 @@
+// Package registry maps names to text processors
+// with documentation and typed parameters.
+//
+// A processor is specified by its name, optionally followed by parameters:
+// "name:param=value,param=value" (see ParsePipeline).
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MihaiB/textproc/v3"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A Processor is a registered text processor.
+type Processor struct {
+	Doc string
+	// Params declares the parameters of New.
+	Params []*Param
+	// RuneProcessor is the processor if it has no parameters.
+	RuneProcessor textproc.RuneProcessor
+	// New, if not nil, builds the RuneProcessor from the parameter values.
+	New func(*Params) (textproc.RuneProcessor, error)
+}
+
+// nameRegexp matches valid processor names.
+// Names are lowercase so that sorting them is case-insensitive.
+var nameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// IsValidName reports if name is a valid processor name:
+// a lowercase letter followed by lowercase letters and digits.
+func IsValidName(name string) bool {
+	return nameRegexp.MatchString(name)
+}
+
+// A Registry maps names to processors.
+type Registry struct {
+	processors map[string]*Processor
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{processors: map[string]*Processor{}}
+}
+
+// Register adds the processor under name.
+// It fails if name is invalid or taken, or if p is inconsistent:
+// it must have either RuneProcessor or New, parameters only with New,
+// unique parameter names and valid defaults.
+func (r *Registry) Register(name string, p *Processor) error {
+	switch {
+	case !nameRegexp.MatchString(name):
+		return fmt.Errorf("invalid processor name: %#v", name)
+	case r.processors[name] != nil:
+		return errors.New("duplicate processor: " + name)
+	case (p.RuneProcessor == nil) == (p.New == nil):
+		return errors.New(name + ": want one of RuneProcessor and New")
+	case len(p.Params) > 0 && p.New == nil:
+		return errors.New(name + ": parameters without New")
+	}
+
+	names := map[string]bool{}
+	for _, decl := range p.Params {
+		if names[decl.Name] {
+			return errors.New(name + ": duplicate parameter: " + decl.Name)
+		}
+		names[decl.Name] = true
+		if decl.Default != "" {
+			if _, err := decl.parseValue(decl.Default); err != nil {
+				return fmt.Errorf("%s: default of %s: %w", name,
+					decl.Name, err)
+			}
+		}
+	}
+
+	r.processors[name] = p
+	return nil
+}
+
+// MustRegister is like Register but panics if registration fails.
+func (r *Registry) MustRegister(name string, p *Processor) {
+	if err := r.Register(name, p); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the processor registered under name.
+func (r *Registry) Lookup(name string) (*Processor, bool) {
+	p, ok := r.processors[name]
+	return p, ok
+}
+
+// Names returns the sorted names of the registered processors.
+func (r *Registry) Names() []string {
+	var names []string
+	for name := range r.processors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SplitSpec splits "name:parameters" into name and parameters.
+func SplitSpec(spec string) (name, params string) {
+	if i := strings.Index(spec, ":"); i >= 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// Build returns the processor specified by spec,
+// a single step of a pipeline specification (see ParsePipeline).
+// Syntax errors are reported as SpecError,
+// other errors are prefixed by the name.
+func (r *Registry) Build(spec string) (textproc.RuneProcessor, error) {
+	pipeline, err := ParsePipeline(spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipeline.Steps) != 1 {
+		return nil, fmt.Errorf("want one processor: %#v", spec)
+	}
+	runeProc, _, err := r.buildStep(pipeline.Steps[0])
+	return runeProc, err
+}
+
+// build returns the processor with the parameter values.
+// Errors are prefixed by the name.
+func (p *Processor) build(name string, values *Params) (
+	textproc.RuneProcessor, error) {
+	if p.New == nil {
+		return p.RuneProcessor, nil
+	}
+	runeProc, err := p.New(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return runeProc, nil
+}
+
+// buildStep returns the processor of step.
+// If an argument is invalid, it also returns that argument.
+// Errors are prefixed by the name.
+func (r *Registry) buildStep(step *Step) (textproc.RuneProcessor, *Arg,
+	error) {
+	p, ok := r.processors[step.Name]
+	if !ok {
+		return nil, nil, errors.New("unknown processor: " + step.Name)
+	}
+	values, arg, err := bindParams(p.Params, step.Args)
+	if err != nil {
+		return nil, arg, fmt.Errorf("%s: %w", step.Name, err)
+	}
+	runeProc, err := p.build(step.Name, values)
+	return runeProc, nil, err
+}
+
+// Clone returns a copy of r, in which more processors can be registered
+// without changing r.
+func (r *Registry) Clone() *Registry {
+	clone := New()
+	for name, p := range r.processors {
+		clone.processors[name] = p
+	}
+	return clone
+}
+
+// Chain returns the processor running runeProcs one after another.
+func Chain(runeProcs ...textproc.RuneProcessor) textproc.RuneProcessor {
+	return func(runeCh <-chan rune, errCh <-chan error) (
+		<-chan rune, <-chan error) {
+		for _, p := range runeProcs {
+			runeCh, errCh = p(runeCh, errCh)
+		}
+		return runeCh, errCh
+	}
+}
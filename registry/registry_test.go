@@ -0,0 +1,185 @@
+package registry_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"github.com/MihaiB/textproc/v3/registry"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinNames(t *testing.T) {
+	r := registry.Builtin()
+	names := r.Names()
+	if len(names) == 0 {
+		t.Fatal("No built-in processors")
+	}
+	for i := 0; i < len(names)-1; i++ {
+		a, b := names[i], names[i+1]
+		if a >= b {
+			t.Fatal("Names not sorted or not unique:", a, "≥", b)
+		}
+	}
+	for _, name := range names {
+		if _, ok := r.Lookup(name); !ok {
+			t.Fatal("Name", name, "not registered")
+		}
+	}
+}
+
+func TestNorm(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":      {"", nil},
+		" \t":   {"", nil},
+		"a \rb": {"a\nb\n", nil},
+	}
+	norm, err := registry.Builtin().Build("norm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, norm, testcases)
+}
+
+func TestNormStrict(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                           {"", nil},
+		"\n\na \r\n\r\n \r\n\nb\n\n": {"a\n\nb\n", nil},
+	}
+	norm, err := registry.Builtin().Build("normstrict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, norm, testcases)
+}
+
+func TestRegister(t *testing.T) {
+	r := registry.New()
+	if err := r.Register("upper", &registry.Processor{Doc: "Upper case",
+		RuneProcessor: textproc.ConvertLineTerminatorsToLF}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, p := range map[string]*registry.Processor{
+		"Upper": {RuneProcessor: textproc.RemoveBOM},
+		"a-b":   {RuneProcessor: textproc.RemoveBOM},
+		"upper": {RuneProcessor: textproc.RemoveBOM},
+		"none":  {},
+		"both": {RuneProcessor: textproc.RemoveBOM,
+			New: func(*registry.Params) (textproc.RuneProcessor, error) {
+				return textproc.RemoveBOM, nil
+			}},
+		"params": {RuneProcessor: textproc.RemoveBOM,
+			Params: []*registry.Param{{Name: "x"}}},
+		"dup": {Params: []*registry.Param{{Name: "x"}, {Name: "x"}},
+			New: func(*registry.Params) (textproc.RuneProcessor, error) {
+				return textproc.RemoveBOM, nil
+			}},
+		"def": {Params: []*registry.Param{{Name: "n",
+			Kind: registry.IntParam, Default: "x"}},
+			New: func(*registry.Params) (textproc.RuneProcessor, error) {
+				return textproc.RemoveBOM, nil
+			}},
+	} {
+		if err := r.Register(name, p); err == nil {
+			t.Error("Want error for", name)
+		}
+	}
+	if got := strings.Join(r.Names(), " "); got != "upper" {
+		t.Fatal("Want", "upper", "got", got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := registry.Builtin()
+	for spec, want := range map[string]string{
+		"nope":                          "unknown processor: nope",
+		"nope:x=1":                      "unknown processor: nope",
+		"lf:x":                          `lf: unknown parameter: "x"`,
+		"cut":                           "cut: missing parameter: fields",
+		"cut:fields=0":                  "cut: " + textproc.ErrInvalidFieldList.Error(),
+		"tabindent:width=0":             "tabindent: width must be at least 1",
+		"sortcsv:column=x":              `sortcsv: column: not an integer: "x"`,
+		"normcsv:comma=ab":              `normcsv: comma: not a single character: "ab"`,
+		`normcsv:comma="\""`:            "normcsv: comma must not be a quote or a line terminator",
+		`normcsv:out-comma="\n"`:        "normcsv: out-comma must not be a quote or a line terminator",
+		`csv2tsv:comma="\r"`:            "csv2tsv: comma must not be a quote or a line terminator",
+		`sortcsv:comma="\n"`:            "sortcsv: comma must not be a quote or a line terminator",
+		`tsv2csv:out-comma="\""`:        "tsv2csv: out-comma must not be a quote or a line terminator",
+		"sortitemsi:unique=yes":         `sortitemsi: unique: not a boolean: "yes"`,
+		"stripinv:allow=x":              `stripinv: invalid code point: "x"`,
+		"show:style=x":                  `show: unknown style: "x"`,
+		"keepsortli:start=":             "keepsortli: empty start marker",
+		"keepsortpi:end=":               "keepsortpi: empty end marker",
+		"squeeze:max=-1":                "squeeze: max must not be negative",
+		"dedent:width=0":                "dedent: width must be at least 1",
+		"dedent:sep=---":                "dedent: sep requires paragraphs",
+		"dedent:blank,paragraphs=false": "dedent: blank requires paragraphs",
+		"sortpi:sep=(":                  "sortpi: error parsing regexp: missing closing ): `(`",
+		"":                              `want one processor: ""`,
+		"lf trail":                      `want one processor: "lf trail"`,
+		`indent:prefix="x`:              "1:15: " + registry.ErrUnterminatedQuote.Error(),
+	} {
+		if p, err := r.Build(spec); err == nil || err.Error() != want ||
+			p != nil {
+			t.Errorf("%#v: want %#v got %v", spec, want, err)
+		}
+	}
+
+	p, err := r.Build("cut:fields=2\\,1,delim=;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"a;b\n": {"b;a\n", nil},
+	})
+
+	p, err = r.Compile("show:style=pictures unshow:style=pictures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"a\t\r\n": {"a\t\r\n", nil},
+	})
+
+	p, err = r.Build("normspace:collapse,keep-nbsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"  a\u2003\u2003b\u00a0c\n": {"  a b\u00a0c\n", nil},
+	})
+
+	p, err = r.Compile("smartpunct asciipunct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		`"a" -- 'b'...` + "\n": {`"a" -- 'b'...` + "\n", nil},
+	})
+
+	p, err = r.Compile(`dedent:paragraphs,width=4 indent:prefix="\t"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"  a\n    b\n\n\tc\n": {"\ta\n\t  b\n\n\tc\n", nil},
+	})
+
+	p, err = r.Compile("sortpi:blank,sep=^---$" +
+		" substp:s=s/\\n/\\,/g,blank,sep=^---$,drop-sep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"d\nc\n---\nb\n \na\n": {"a\n\nb\n\nd,c\n", nil},
+	})
+
+	p, err = r.Build("escinv:allow=U+00AD,allow=200b,emoji-zwj=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.CheckRuneProcessor(t, p, internal.RuneProcessorTestCases{
+		"\u00ad\u200b\u2060\U0001f469\u200d\U0001f4bb": {
+			"\u00ad\u200b<U+2060>\U0001f469<U+200D>\U0001f4bb", nil},
+	})
+}
@@ -0,0 +1,180 @@
+package textproc
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// A ShowStyle selects the markers of Show.
+type ShowStyle int
+
+const (
+	// CaretNotation shows control characters as ^X, like cat -A:
+	// ^I for tab, ^M for CR, ^? for DEL, and the end of lines as $.
+	CaretNotation ShowStyle = iota
+	// ControlPictures shows control characters as Unicode control
+	// pictures: ␉ for tab, ␍ for CR, ␡ for DEL,
+	// and the end of lines as ␊.
+	ControlPictures
+)
+
+// Control pictures for C0 control characters start at pictureNUL.
+const (
+	pictureNUL = '␀'
+	pictureDEL = '␡'
+)
+
+// showEscaped reports if Show writes r as <U+XXXX>:
+// other spaces than ASCII, other control characters and invisible runes.
+func showEscaped(r rune) bool {
+	return r > unicode.MaxASCII &&
+		(unicode.IsSpace(r) || unicode.IsControl(r) || IsInvisible(r))
+}
+
+// parseShownCodePoint parses the <U+XXXX> at the start of runes,
+// as written by Show, and returns the rune and the length of the text.
+func parseShownCodePoint(runes []rune) (r rune, length int, ok bool) {
+	const prefix = "<U+"
+	for i := range prefix {
+		if i == len(runes) || runes[i] != rune(prefix[i]) {
+			return
+		}
+	}
+	end := len(prefix)
+	for end < len(runes) && end < len(prefix)+6 &&
+		(runes[end] >= '0' && runes[end] <= '9' ||
+			runes[end] >= 'A' && runes[end] <= 'F') {
+		end++
+	}
+	if end-len(prefix) < 4 || end == len(runes) || runes[end] != '>' {
+		return
+	}
+	n, err := strconv.ParseUint(string(runes[len(prefix):end]), 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return
+	}
+	return rune(n), end + 1, true
+}
+
+// showRune returns the marker of r, or r if it is shown as is.
+func showRune(style ShowStyle, r rune) []rune {
+	switch {
+	case r < ' ' || r == unicode.MaxASCII:
+		if style == ControlPictures {
+			if r == unicode.MaxASCII {
+				return []rune{pictureDEL}
+			}
+			return []rune{pictureNUL + r}
+		}
+		return []rune{'^', r ^ 0x40}
+	case showEscaped(r) ||
+		style == ControlPictures && r >= pictureNUL && r <= pictureDEL:
+		return []rune("<" + FormatCodePoint(r) + ">")
+	default:
+		return []rune{r}
+	}
+}
+
+// isCaretControl reports if r follows ^ in the caret notation of a control.
+func isCaretControl(r rune) bool {
+	return r >= '@' && r <= '_' || r == '?'
+}
+
+// showLine returns the markers of the runes of line,
+// which does not include a line terminator.
+// Runes which the markers could be mistaken for are written as <U+XXXX>.
+func showLine(style ShowStyle, line []rune) []rune {
+	shown := make([][]rune, len(line))
+	for i, r := range line {
+		shown[i] = showRune(style, r)
+	}
+
+	var out []rune
+	for i, r := range line {
+		var ambiguous bool
+		switch r {
+		case '<':
+			_, _, ambiguous = parseShownCodePoint(line[i:])
+		case '^':
+			ambiguous = style == CaretNotation && i+1 < len(line) &&
+				isCaretControl(shown[i+1][0])
+		}
+		if ambiguous {
+			out = append(out, []rune("<"+FormatCodePoint(r)+">")...)
+		} else {
+			out = append(out, shown[i]...)
+		}
+	}
+	return out
+}
+
+// lineEndMarker returns the marker written by Show before "\n".
+func lineEndMarker(style ShowStyle) rune {
+	if style == ControlPictures {
+		return pictureNUL + '\n'
+	}
+	return '$'
+}
+
+// Show makes control characters, other spaces than ASCII,
+// invisible characters, the byte order mark and the end of lines visible,
+// with the markers of style and <U+XXXX>.
+// Unshow reverses it: runes which a marker could be mistaken for
+// are also written as <U+XXXX>.
+func Show(style ShowStyle) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLines(runeIn, errIn,
+			func(line []rune, terminated bool) []rune {
+				line = showLine(style, line)
+				if terminated {
+					line = append(line, lineEndMarker(style), '\n')
+				}
+				return line
+			})
+	}
+}
+
+// unshowLine returns the runes of the markers of style in line.
+func unshowLine(style ShowStyle, line []rune) []rune {
+	var out []rune
+	for i := 0; i < len(line); i++ {
+		r := line[i]
+		if shown, length, ok := parseShownCodePoint(line[i:]); ok {
+			out = append(out, shown)
+			i += length - 1
+			continue
+		}
+
+		switch {
+		case style == CaretNotation && r == '^' && i+1 < len(line) &&
+			isCaretControl(line[i+1]):
+			i++
+			out = append(out, line[i]^0x40)
+		case style == ControlPictures && r == pictureDEL:
+			out = append(out, unicode.MaxASCII)
+		case style == ControlPictures && r >= pictureNUL && r < pictureNUL+' ':
+			out = append(out, r-pictureNUL)
+		default:
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Unshow reverses Show with the same style.
+func Unshow(style ShowStyle) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLines(runeIn, errIn,
+			func(line []rune, terminated bool) []rune {
+				if !terminated {
+					return unshowLine(style, line)
+				}
+				if n := len(line); n > 0 && line[n-1] == lineEndMarker(style) {
+					line = line[:n-1]
+				}
+				return append(unshowLine(style, line), '\n')
+			})
+	}
+}
@@ -0,0 +1,74 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestShowCaretNotation(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                       {"", nil},
+		"a \t\r\n\n":             {"a ^I^M$\n$\n", nil},
+		"\ufeffx\u00a0y\x7f\x00": {"<U+FEFF>x<U+00A0>y^?^@", nil},
+		"\u200b\u0085\u3000":     {"<U+200B><U+0085><U+3000>", nil},
+		"$ ^ ^[ ^\t^$\n":         {"$ ^ <U+005E>[ <U+005E>^I^$$\n", nil},
+		"<U+0041> <U+00A <U+x>":  {"<U+003C>U+0041> <U+00A <U+x>", nil},
+		"␊␉é\xff":                {"", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.Show(textproc.CaretNotation),
+		testcases)
+}
+
+func TestShowControlPictures(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"a \t\r\n\n":         {"a ␉␍␊\n␊\n", nil},
+		"\x7f\x00\x1f\u00a0": {"␡␀␟<U+00A0>", nil},
+		"^[$␊\n␉":            {"^[$<U+240A>␊\n<U+2409>", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.Show(textproc.ControlPictures),
+		testcases)
+}
+
+func TestUnshow(t *testing.T) {
+	internal.CheckRuneProcessor(t, textproc.Unshow(textproc.CaretNotation),
+		internal.RuneProcessorTestCases{
+			"a^I$\nb\n^":          {"a\t\nb\n^", nil},
+			"<U+1F469><U+0041>$$": {"\U0001f469A$$", nil},
+			"^@^?^^\n\xff":        {"\x00\x7f\x1e\n", textproc.ErrInvalidUTF8},
+		})
+	internal.CheckRuneProcessor(t, textproc.Unshow(textproc.ControlPictures),
+		internal.RuneProcessorTestCases{
+			"a␉␊\nb\n␡":    {"a\t\nb\n\x7f", nil},
+			"^I$␊<U+240A>": {"^I$\n␊", nil},
+		})
+}
+
+func TestShowRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain text\n",
+		"a \t\r\n\r\n\n  \n",
+		"\ufeff\u00a0\u2009\u200b\u200d\u202e\u3000\x00\x1b[0m\x7f\u0085",
+		"^^ ^@ ^? ^\t^\n^",
+		"$ $$\n$\n$",
+		"<U+0041> <U+1F469> <U+110000> <U+00a0> <<U+0041>> <U+",
+		"␀␉␊␍␡␠ ^␉",
+	}
+	for _, style := range []textproc.ShowStyle{textproc.CaretNotation,
+		textproc.ControlPictures} {
+		roundTrip := func(runeIn <-chan rune, errIn <-chan error) (
+			<-chan rune, <-chan error) {
+			return textproc.Unshow(style)(textproc.Show(style)(runeIn,
+				errIn))
+		}
+		testcases := internal.RuneProcessorTestCases{}
+		for _, in := range inputs {
+			testcases[in] = &struct {
+				String string
+				Error  error
+			}{in, nil}
+		}
+		internal.CheckRuneProcessor(t, roundTrip, testcases)
+	}
+}
@@ -0,0 +1,50 @@
+package textproc
+
+import "unicode"
+
+// noBreakSpaces are the spaces which forbid a line break,
+// used for example before punctuation in French.
+var noBreakSpaces = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00a0, 0x00a0, 1},
+		{0x2007, 0x2007, 1},
+		{0x202f, 0x202f, 1},
+	},
+}
+
+// SpaceOptions configure NormalizeLFSpaces.
+type SpaceOptions struct {
+	// Collapse replaces each run of spaces after the indentation
+	// of a line with one space.
+	Collapse bool
+	// KeepNoBreak keeps the no-break spaces U+00A0, U+2007 and U+202F.
+	KeepNoBreak bool
+}
+
+// NormalizeLFSpaces replaces the spaces of category Zs,
+// such as no-break, thin, hair, em and ideographic spaces, with " ".
+// Lines are terminated by "\n".
+func NormalizeLFSpaces(opts SpaceOptions) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, func(line []rune) []rune {
+			var out []rune
+			indent := true
+			for _, r := range line {
+				if unicode.Is(unicode.Zs, r) &&
+					!(opts.KeepNoBreak && unicode.Is(noBreakSpaces, r)) {
+					r = ' '
+				}
+				if r != ' ' && r != '\t' {
+					indent = false
+				}
+				if opts.Collapse && !indent && r == ' ' &&
+					len(out) > 0 && out[len(out)-1] == ' ' {
+					continue
+				}
+				out = append(out, r)
+			}
+			return out
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestNormalizeLFSpaces(t *testing.T) {
+	internal.CheckRuneProcessor(t,
+		textproc.NormalizeLFSpaces(textproc.SpaceOptions{}),
+		internal.RuneProcessorTestCases{
+			"": {"", nil},
+			"a\u00a0b\u2009c\u200ad\u2003e\u3000f\u202fg\u205fh\n": {
+				"a b c d e f g h\n", nil},
+			"\u3000\u3000a  b\t\u200bc\r\n\xff": {
+				"  a  b\t\u200bc\r\n", textproc.ErrInvalidUTF8},
+		})
+
+	internal.CheckRuneProcessor(t,
+		textproc.NormalizeLFSpaces(textproc.SpaceOptions{Collapse: true}),
+		internal.RuneProcessorTestCases{
+			"    a   b\u00a0 c \n\t \u2003x\u2003 \n": {
+				"    a b c \n\t  x \n", nil},
+			"a \t  b": {"a \t b", nil},
+		})
+
+	internal.CheckRuneProcessor(t,
+		textproc.NormalizeLFSpaces(textproc.SpaceOptions{Collapse: true,
+			KeepNoBreak: true}),
+		internal.RuneProcessorTestCases{
+			"Oui\u202f! 1\u2007000\u00a0\u00a0€  x\u2002\u2002y": {
+				"Oui\u202f! 1\u2007000\u00a0\u00a0€ x y", nil},
+		})
+}
@@ -0,0 +1,120 @@
+package textproc
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Stats are statistics of a text.
+//
+// Lines are terminated by "\n", "\r\n" or "\r";
+// a last line without a terminator is counted if it is not empty.
+// The byte order mark is not part of the first line.
+type Stats struct {
+	Bytes int `json:"bytes"`
+	Runes int `json:"runes"`
+	Lines int `json:"lines"`
+	// Paragraphs are counted like ReadLFParagraphContent reads them.
+	Paragraphs int `json:"paragraphs"`
+	// Words are sequences of runes other than white space.
+	Words int `json:"words"`
+	// LongestLine is the width of the longest line in display columns,
+	// with tab stops every 8 columns.
+	LongestLine int `json:"longest_line"`
+	// LF, CRLF and CR count each kind of line terminator.
+	LF   int `json:"lf"`
+	CRLF int `json:"crlf"`
+	CR   int `json:"cr"`
+	// TrailingWhiteSpaceLines counts lines ending in white space.
+	TrailingWhiteSpaceLines int `json:"trailing_white_space_lines"`
+	// TabIndentedLines and SpaceIndentedLines count lines
+	// starting with a tab and with a space.
+	TabIndentedLines   int `json:"tab_indented_lines"`
+	SpaceIndentedLines int `json:"space_indented_lines"`
+	// BOM reports if the text starts with a byte order mark.
+	BOM bool `json:"bom"`
+	// FinalNewline reports if the text ends with a line terminator.
+	FinalNewline bool `json:"final_newline"`
+}
+
+// addLine adds the statistics of the content of a line.
+// inParagraph reports if the previous line was not empty.
+func (s *Stats) addLine(line []rune, inParagraph bool) {
+	s.Lines++
+	if len(line) == 0 {
+		return
+	}
+	if !inParagraph {
+		s.Paragraphs++
+	}
+
+	width := 0
+	for _, r := range line {
+		width = advanceColumn(width, r)
+	}
+	if width > s.LongestLine {
+		s.LongestLine = width
+	}
+	if unicode.IsSpace(line[len(line)-1]) {
+		s.TrailingWhiteSpaceLines++
+	}
+	switch line[0] {
+	case '\t':
+		s.TabIndentedLines++
+	case ' ':
+		s.SpaceIndentedLines++
+	}
+}
+
+// ReadStats reads all runes and returns their statistics.
+func ReadStats(runeIn <-chan rune, errIn <-chan error) (*Stats, error) {
+	s := &Stats{}
+	var line []rune
+	afterCR, inParagraph, inWord := false, false, false
+
+	for r := range runeIn {
+		s.Runes++
+		s.Bytes += utf8.RuneLen(r)
+		if s.Runes == 1 && r == bom {
+			s.BOM = true
+			continue
+		}
+
+		s.FinalNewline = r == '\n' || r == '\r'
+		if afterCR && r == '\n' {
+			s.CR--
+			s.CRLF++
+			afterCR = false
+			continue
+		}
+		afterCR = r == '\r'
+
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			s.Words++
+			inWord = true
+		}
+
+		switch r {
+		case '\n':
+			s.LF++
+		case '\r':
+			s.CR++
+		default:
+			line = append(line, r)
+			continue
+		}
+		s.addLine(line, inParagraph)
+		inParagraph = len(line) != 0
+		line = nil
+	}
+
+	if err := <-errIn; err != nil {
+		return nil, err
+	}
+	if len(line) != 0 {
+		s.addLine(line, inParagraph)
+	}
+	return s, nil
+}
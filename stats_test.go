@@ -0,0 +1,38 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"strings"
+	"testing"
+)
+
+func TestReadStats(t *testing.T) {
+	for in, want := range map[string]textproc.Stats{
+		"":       {},
+		"\ufeff": {Bytes: 3, Runes: 1, BOM: true},
+		"a b\n": {Bytes: 4, Runes: 4, Lines: 1, Paragraphs: 1, Words: 2,
+			LongestLine: 3, LF: 1, FinalNewline: true},
+		"\ufeff\tx \r\n\r\n  é\rlast": {Bytes: 19, Runes: 16, Lines: 4,
+			Paragraphs: 2, Words: 3, LongestLine: 10, CRLF: 2, CR: 1,
+			TrailingWhiteSpaceLines: 1, TabIndentedLines: 1,
+			SpaceIndentedLines: 1, BOM: true},
+		"世界\n\n\n a\nb\r\r": {Bytes: 15, Runes: 11, Lines: 6,
+			Paragraphs: 2, Words: 3, LongestLine: 4, LF: 4, CR: 2,
+			SpaceIndentedLines: 1, FinalNewline: true},
+	} {
+		got, err := textproc.ReadStats(textproc.ReadRunes(
+			strings.NewReader(in)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != want {
+			t.Errorf("%#v: want %+v got %+v", in, want, *got)
+		}
+	}
+
+	if s, err := textproc.ReadStats(textproc.ReadRunes(
+		strings.NewReader("a\n\xff"))); err != textproc.ErrInvalidUTF8 ||
+		s != nil {
+		t.Fatal("Want", textproc.ErrInvalidUTF8, "got", s, err)
+	}
+}
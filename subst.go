@@ -0,0 +1,159 @@
+package textproc
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidSubstitution is the error returned
+// when a substitution expression cannot be parsed.
+var ErrInvalidSubstitution = errors.New("invalid substitution expression")
+
+// A Substitution replaces the matches of a regular expression.
+type Substitution struct {
+	Regexp *regexp.Regexp
+	// Replacement can refer to submatches as $1 or ${name},
+	// see regexp.Regexp.Expand, unless Literal is true.
+	Replacement string
+	// Literal inserts Replacement verbatim.
+	Literal bool
+	// Global replaces all the matches, not only the first one.
+	Global bool
+}
+
+func (s *Substitution) replace(text string) string {
+	if s.Global {
+		if s.Literal {
+			return s.Regexp.ReplaceAllLiteralString(text, s.Replacement)
+		}
+		return s.Regexp.ReplaceAllString(text, s.Replacement)
+	}
+
+	match := s.Regexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text
+	}
+	result := []byte(text[:match[0]])
+	if s.Literal {
+		result = append(result, s.Replacement...)
+	} else {
+		result = s.Regexp.ExpandString(result, s.Replacement, text, match)
+	}
+	return string(append(result, text[match[1]:]...))
+}
+
+func substitute(subs []*Substitution) func([]rune) []rune {
+	return func(text []rune) []rune {
+		s := string(text)
+		for _, sub := range subs {
+			s = sub.replace(s)
+		}
+		return []rune(s)
+	}
+}
+
+// splitSubstitution splits expr on the unescaped occurrences of delim.
+// An escaped delimiter loses its backslash,
+// all other escape sequences are kept unchanged.
+func splitSubstitution(expr string, delim rune) []string {
+	var parts []string
+	var crt strings.Builder
+	escaped := false
+	for _, r := range expr {
+		switch {
+		case escaped:
+			if r != delim {
+				crt.WriteRune('\\')
+			}
+			crt.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == delim:
+			parts = append(parts, crt.String())
+			crt.Reset()
+		default:
+			crt.WriteRune(r)
+		}
+	}
+	if escaped {
+		crt.WriteRune('\\')
+	}
+	return append(parts, crt.String())
+}
+
+// ParseSubstitution parses an expression like sed's "s/pattern/replacement/".
+//
+// Any character other than backslash and newline can be used
+// as the delimiter instead of "/"; it is the character after "s".
+// A backslash before the delimiter makes the delimiter literal.
+// The pattern uses the syntax of package regexp and the replacement can
+// refer to submatches as $1 or ${name}.
+//
+// The final delimiter can be followed by flags:
+// "g" replaces all the matches (otherwise only the first one),
+// "i" matches case-insensitive,
+// "l" treats pattern and replacement as literal text.
+func ParseSubstitution(expr string) (*Substitution, error) {
+	if !strings.HasPrefix(expr, "s") {
+		return nil, ErrInvalidSubstitution
+	}
+	delim, size := utf8.DecodeRuneInString(expr[1:])
+	if size == 0 || delim == '\\' || delim == '\n' || delim == utf8.RuneError {
+		return nil, ErrInvalidSubstitution
+	}
+
+	parts := splitSubstitution(expr[1+size:], delim)
+	if len(parts) != 3 {
+		return nil, ErrInvalidSubstitution
+	}
+	pattern, sub := parts[0], &Substitution{Replacement: parts[1]}
+
+	caseInsensitive := false
+	for _, flag := range parts[2] {
+		switch flag {
+		case 'g':
+			sub.Global = true
+		case 'i':
+			caseInsensitive = true
+		case 'l':
+			sub.Literal = true
+		default:
+			return nil, ErrInvalidSubstitution
+		}
+	}
+
+	if sub.Literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sub.Regexp = re
+	return sub, nil
+}
+
+// SubstituteLFLines applies the substitutions, in order,
+// to the content of each line.
+// Lines are terminated by "\n".
+func SubstituteLFLines(subs ...*Substitution) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		return mapLFLines(runeIn, errIn, substitute(subs))
+	}
+}
+
+// SubstituteLFParagraphs applies the substitutions, in order,
+// to the content of each paragraph as read by ReadLFParagraphContent,
+// so a pattern can match across the lines of a paragraph.
+// Empty lines are kept unchanged.
+// See LFParagraphs.Substitute for other paragraph separators.
+func SubstituteLFParagraphs(subs ...*Substitution) RuneProcessor {
+	return LFParagraphs{}.Substitute(subs...)
+}
@@ -0,0 +1,85 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func mustParseSubstitutions(t *testing.T, exprs ...string) []*textproc.Substitution {
+	var subs []*textproc.Substitution
+	for _, expr := range exprs {
+		sub, err := textproc.ParseSubstitution(expr)
+		if err != nil {
+			t.Fatal("Cannot parse", expr, err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func TestParseSubstitution(t *testing.T) {
+	for _, expr := range []string{
+		"", "s", "s/", "s/a", "s/a/b", "s/a/b/c/", "s/a/b/x",
+		"x/a/b/", "s\\a\\b\\", "s/(/b/",
+	} {
+		if sub, err := textproc.ParseSubstitution(expr); err == nil {
+			t.Fatalf("Want error for %#v got %#v", expr, sub)
+		}
+	}
+
+	for expr, want := range map[string]*struct {
+		pattern, replacement string
+		literal, global      bool
+	}{
+		"s/a/b/":         {"a", "b", false, false},
+		"s/a/b/g":        {"a", "b", false, true},
+		"s|a/b|\\|$1|gl": {`a/b`, "|$1", true, true},
+		"s,x\\,y,z,i":    {"(?i)x,y", "z", false, false},
+		"s/\\d\\//-/":    {`\d/`, "-", false, false},
+		"s/./·/l":        {`\.`, "·", true, false},
+	} {
+		sub, err := textproc.ParseSubstitution(expr)
+		if err != nil {
+			t.Fatalf("Want nil for %#v got %v", expr, err)
+		}
+		if got := sub.Regexp.String(); got != want.pattern {
+			t.Fatalf("Want %#v got %#v", want.pattern, got)
+		}
+		if sub.Replacement != want.replacement ||
+			sub.Literal != want.literal || sub.Global != want.global {
+			t.Fatalf("Want %#v got %#v", want, sub)
+		}
+	}
+}
+
+func TestSubstituteLFLines(t *testing.T) {
+	subs := mustParseSubstitutions(t,
+		`s/(\w+)=(\w+)/$2=$1/g`, "s/^ +//", "s/$/;/", "s/$1/€/l")
+	testcases := internal.RuneProcessorTestCases{
+		"":                 {"", nil},
+		"\n\n":             {";\n;\n", nil},
+		"a=b c=d\n  x=y":   {"b=a d=c;\ny=x;", nil},
+		"$1 $1\n\n":        {"€ $1;\n;\n", nil},
+		"ab\r\n":           {"ab\r;\n", nil},
+		"k=v\n  z=w\xff\n": {"v=k;\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SubstituteLFLines(subs...),
+		testcases)
+
+	internal.CheckRuneProcessor(t, textproc.SubstituteLFLines(),
+		internal.RuneProcessorTestCases{"a\nb": {"a\nb", nil}})
+}
+
+func TestSubstituteLFParagraphs(t *testing.T) {
+	subs := mustParseSubstitutions(t, `s/\n/ /g`, "s/^/> /")
+	testcases := internal.RuneProcessorTestCases{
+		"":                     {"", nil},
+		"\n":                   {"\n", nil},
+		"a\nb\n\n\nc\n":        {"> a b\n\n\n> c\n", nil},
+		"\na\nb":               {"\n> a b", nil},
+		"x\ny\n\nz\xff\n\nq\n": {"> x y\n\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SubstituteLFParagraphs(subs...),
+		testcases)
+}
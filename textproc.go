@@ -10,6 +10,7 @@ package textproc
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"sort"
 	"unicode"
@@ -19,6 +20,22 @@ import (
 // ErrInvalidUTF8 is the error returned when the input is not valid UTF-8.
 var ErrInvalidUTF8 = errors.New("invalid UTF-8")
 
+// A LineError is an error at a line of the input.
+type LineError struct {
+	// Line counts from 1.
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprint("line ", e.Line, ": ", e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
 const runeErrorSize = len(string(utf8.RuneError))
 
 // A RuneProcessor consumes and produces runes.
@@ -29,30 +46,35 @@ type RuneProcessor = func(runeIn <-chan rune, errIn <-chan error) (
 type Tokenizer = func(runeIn <-chan rune, errIn <-chan error) (
 	tokenOut <-chan []rune, errOut <-chan error)
 
-type tokenLowercaseT struct {
-	token     []rune
-	lowercase string
-}
-
-func getTokenLowercaseT(token []rune) *tokenLowercaseT {
+func toLowerRunes(token []rune) string {
 	lowerRunes := make([]rune, len(token))
 	for i := range token {
 		lowerRunes[i] = unicode.ToLower(token[i])
 	}
-	return &tokenLowercaseT{token, string(lowerRunes)}
+	return string(lowerRunes)
 }
 
-func sortTokensI(tokens [][]rune) {
-	lowercaseTokens := make([]*tokenLowercaseT, len(tokens))
-	for i := range tokens {
-		lowercaseTokens[i] = getTokenLowercaseT(tokens[i])
+// sortIndicesI returns the indices of keys
+// in the stable case-insensitive order of the keys.
+func sortIndicesI(keys [][]rune) []int {
+	lowercaseKeys := make([]string, len(keys))
+	indices := make([]int, len(keys))
+	for i := range keys {
+		lowercaseKeys[i] = toLowerRunes(keys[i])
+		indices[i] = i
 	}
-	sort.SliceStable(lowercaseTokens, func(i, j int) bool {
-		return lowercaseTokens[i].lowercase < lowercaseTokens[j].lowercase
+	sort.SliceStable(indices, func(i, j int) bool {
+		return lowercaseKeys[indices[i]] < lowercaseKeys[indices[j]]
 	})
-	for i := range lowercaseTokens {
-		tokens[i] = lowercaseTokens[i].token
+	return indices
+}
+
+func sortTokensI(tokens [][]rune) {
+	sorted := make([][]rune, len(tokens))
+	for i, j := range sortIndicesI(tokens) {
+		sorted[i] = tokens[j]
 	}
+	copy(tokens, sorted)
 }
 
 func readRune(reader io.RuneReader) (rune, error) {
@@ -227,6 +249,52 @@ func TrimTrailingEmptyLFLines(runeIn <-chan rune, errIn <-chan error) (
 	return runeOut, errIn
 }
 
+// SqueezeEmptyLFLines replaces each run of more than max lines
+// which are empty or only contain white space with its first max lines.
+// Lines are terminated by "\n".
+func SqueezeEmptyLFLines(max int) RuneProcessor {
+	return func(runeIn <-chan rune, errIn <-chan error) (
+		<-chan rune, <-chan error) {
+		runeOut := make(chan rune)
+
+		go func() {
+			// spaces holds the line while it only contains white space.
+			var spaces []rune
+			inContent, blanks := false, 0
+
+			for r := range runeIn {
+				switch {
+				case r == '\n' && !inContent:
+					if blanks++; blanks <= max {
+						writeRunes(runeOut, spaces)
+						runeOut <- r
+					}
+					spaces = nil
+				case r == '\n':
+					runeOut <- r
+					inContent, blanks = false, 0
+				case inContent:
+					runeOut <- r
+				case unicode.IsSpace(r):
+					spaces = append(spaces, r)
+				default:
+					writeRunes(runeOut, spaces)
+					spaces = nil
+					runeOut <- r
+					inContent = true
+				}
+			}
+			if blanks < max {
+				writeRunes(runeOut, spaces)
+			}
+
+			close(runeOut)
+		}()
+
+		return runeOut, errIn
+	}
+}
+
 // ReadLFLineContent reads the content of each line.
 // The content does not include the line terminator.
 // Lines are terminated by "\n".
@@ -285,74 +353,102 @@ func SortLFLinesI(runeIn <-chan rune, errIn <-chan error) (
 	return runeOut, errIn
 }
 
-// ReadLFParagraphContent reads the content of each paragraph.
-// The content does not include the line terminator
-// of the paragraph's last line.
-//
-// A paragraph consists of adjacent non-empty lines.
+func writeRunes(runeOut chan<- rune, runes []rune) {
+	for _, r := range runes {
+		runeOut <- r
+	}
+}
+
+// mapLines applies fn to the content of each line and reports
+// if the line is terminated by "\n", which fn writes.
 // Lines are terminated by "\n".
-func ReadLFParagraphContent(runeIn <-chan rune, errIn <-chan error) (
-	<-chan []rune, <-chan error) {
-	lineIn, errIn := ReadLFLineContent(runeIn, errIn)
-	tokenOut, errOut := make(chan []rune), make(chan error)
+func mapLines(runeIn <-chan rune, errIn <-chan error,
+	fn func(line []rune, terminated bool) []rune) (<-chan rune, <-chan error) {
+	runeOut, errOut := make(chan rune), make(chan error)
 
 	go func() {
-		var par []rune
-
-		for line := range lineIn {
-			if len(line) != 0 {
-				if len(par) > 0 {
-					par = append(par, '\n')
-				}
-				par = append(par, line...)
+		var line []rune
+		for r := range runeIn {
+			if r == '\n' {
+				writeRunes(runeOut, fn(line, true))
+				line = nil
 				continue
 			}
-
-			if len(par) != 0 {
-				tokenOut <- par
-				par = nil
-			}
+			line = append(line, r)
 		}
 
 		err := <-errIn
-		if err == nil && len(par) != 0 {
-			tokenOut <- par
+		if err == nil && len(line) > 0 {
+			writeRunes(runeOut, fn(line, false))
 		}
-		close(tokenOut)
+		close(runeOut)
 		errOut <- err
 		close(errOut)
 	}()
 
-	return tokenOut, errOut
+	return runeOut, errOut
 }
 
-// SortLFParagraphsI reads the content of all paragraphs
-// using ReadLFParagraphContent,
-// sorts the items in case-insensitive order, joins them with "\n\n"
-// and adds "\n" after the last one.
-func SortLFParagraphsI(runeIn <-chan rune, errIn <-chan error) (
-	<-chan rune, <-chan error) {
-	parIn, errIn := ReadLFParagraphContent(runeIn, errIn)
-	runeOut := make(chan rune)
+// mapLFLines applies fn to the content of each line
+// keeping the line terminators unchanged.
+// Lines are terminated by "\n".
+func mapLFLines(runeIn <-chan rune, errIn <-chan error,
+	fn func(line []rune) []rune) (<-chan rune, <-chan error) {
+	return mapLines(runeIn, errIn,
+		func(line []rune, terminated bool) []rune {
+			line = fn(line)
+			if terminated {
+				// Copy to keep the array returned by fn unchanged.
+				line = append(line[:len(line):len(line)], '\n')
+			}
+			return line
+		})
+}
+
+// mapAll applies fn to the entire input.
+func mapAll(runeIn <-chan rune, errIn <-chan error,
+	fn func(text []rune) []rune) (<-chan rune, <-chan error) {
+	runeOut, errOut := make(chan rune), make(chan error)
 
 	go func() {
-		var paragraphs [][]rune
-		for par := range parIn {
-			paragraphs = append(paragraphs, par)
+		var text []rune
+		for r := range runeIn {
+			text = append(text, r)
 		}
-		sortTokensI(paragraphs)
 
-		for i, par := range paragraphs {
-			if i > 0 {
-				runeOut <- '\n'
-			}
-			for _, char := range par {
-				runeOut <- char
-			}
-			runeOut <- '\n'
+		err := <-errIn
+		if err == nil {
+			writeRunes(runeOut, fn(text))
 		}
 		close(runeOut)
+		errOut <- err
+		close(errOut)
 	}()
 
-	return runeOut, errIn
+	return runeOut, errOut
+}
+
+// drain consumes the remaining input of a processor which stopped early.
+func drain(runeIn <-chan rune, errIn <-chan error) {
+	for range runeIn {
+	}
+	<-errIn
+}
+
+// processRunes runs p on runes and returns the entire output.
+func processRunes(p RuneProcessor, runes []rune) ([]rune, error) {
+	runeIn, errIn := make(chan rune), make(chan error, 1)
+	go func() {
+		writeRunes(runeIn, runes)
+		close(runeIn)
+		errIn <- nil
+		close(errIn)
+	}()
+
+	runeOut, errOut := p(runeIn, errIn)
+	var out []rune
+	for r := range runeOut {
+		out = append(out, r)
+	}
+	return out, <-errOut
 }
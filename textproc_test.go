@@ -92,6 +92,32 @@ func TestTrimTrailingEmptyLFLines(t *testing.T) {
 	internal.CheckRuneProcessor(t, textproc.TrimTrailingEmptyLFLines, testcases)
 }
 
+func TestSqueezeEmptyLFLines(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":                     {"", nil},
+		"\n\n\na\n":            {"\na\n", nil},
+		"a\n\n\n\nb\n\nc\n":    {"a\n\nb\n\nc\n", nil},
+		"a\n \t\n\n  \nb":      {"a\n \t\nb", nil},
+		"a\n\n \n":             {"a\n\n", nil},
+		"a\n\n ":               {"a\n\n", nil},
+		"a\n ":                 {"a\n ", nil},
+		"  a \n\n\n\r\n":       {"  a \n\n", nil},
+		"a\n\n\n\nb\n\n\n\xcc": {"a\n\nb\n\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SqueezeEmptyLFLines(1), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"a\n\n\n\n\nb\n": {"a\n\n\nb\n", nil},
+		"\n \n\n":        {"\n \n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SqueezeEmptyLFLines(2), testcases)
+
+	testcases = internal.RuneProcessorTestCases{
+		"\na\n\n \nb\n ": {"a\nb\n", nil},
+	}
+	internal.CheckRuneProcessor(t, textproc.SqueezeEmptyLFLines(0), testcases)
+}
+
 func TestReadLFLineContent(t *testing.T) {
 	testcases := internal.TokenizerTestCases{
 		"":          {nil, nil},
@@ -0,0 +1,93 @@
+package textproc
+
+import "unicode"
+
+// A treeNode is a line with the lines indented below it.
+type treeNode struct {
+	// lines holds the line of the node and the empty lines after it.
+	lines    [][]rune
+	key      []rune
+	children []*treeNode
+}
+
+// indentation returns the display width of the leading white space of line
+// and the rest of the line.
+func indentation(line []rune) (int, []rune) {
+	col := 0
+	for i, r := range line {
+		if !unicode.IsSpace(r) {
+			return col, line[i:]
+		}
+		col = advanceColumn(col, r)
+	}
+	return col, nil
+}
+
+func (node *treeNode) sortI() {
+	keys := make([][]rune, len(node.children))
+	for i, child := range node.children {
+		keys[i] = child.key
+		child.sortI()
+	}
+	sorted := make([]*treeNode, len(node.children))
+	for i, j := range sortIndicesI(keys) {
+		sorted[i] = node.children[j]
+	}
+	node.children = sorted
+}
+
+func (node *treeNode) write(runeOut chan<- rune) {
+	for _, line := range node.lines {
+		writeRunes(runeOut, line)
+		runeOut <- '\n'
+	}
+	for _, child := range node.children {
+		child.write(runeOut)
+	}
+}
+
+// SortLFTreeI reads the content of all lines using ReadLFLineContent
+// and sorts them as a tree where the children of a line are the lines
+// after it with greater indentation.
+// The siblings at every level are sorted in case-insensitive order
+// of their content after indentation,
+// each one moving together with its descendants.
+// Lines containing only white space stay after the line before them.
+// It adds "\n" after each line.
+func SortLFTreeI(runeIn <-chan rune, errIn <-chan error) (
+	<-chan rune, <-chan error) {
+	lineIn, errIn := ReadLFLineContent(runeIn, errIn)
+	runeOut := make(chan rune)
+
+	go func() {
+		root := &treeNode{}
+		type level struct {
+			indent int
+			node   *treeNode
+		}
+		stack := []level{{-1, root}}
+		last := root
+
+		for line := range lineIn {
+			indent, key := indentation(line)
+			if len(key) == 0 {
+				last.lines = append(last.lines, line)
+				continue
+			}
+
+			for stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1].node
+			last = &treeNode{lines: [][]rune{line}, key: key}
+			parent.children = append(parent.children, last)
+			stack = append(stack, level{indent, last})
+		}
+
+		root.sortI()
+		root.write(runeOut)
+		close(runeOut)
+	}()
+
+	return runeOut, errIn
+}
@@ -0,0 +1,23 @@
+package textproc_test
+
+import (
+	"github.com/MihaiB/textproc/v3"
+	"github.com/MihaiB/textproc/v3/internal"
+	"testing"
+)
+
+func TestSortLFTreeI(t *testing.T) {
+	testcases := internal.RuneProcessorTestCases{
+		"":           {"", nil},
+		"b\nA\nc":    {"A\nb\nc\n", nil},
+		"\n\nb\na\n": {"\n\na\nb\n", nil},
+		"- b\n  - z\n  - y\n- a\n  - x\n    - 2\n    - 1\n": {
+			"- a\n  - x\n    - 1\n    - 2\n- b\n  - y\n  - z\n", nil},
+		"b:\n\tz\n    y\na:\n": {"a:\nb:\n    y\n\tz\n", nil},
+		"b\n  d\n\n  c\na\n":   {"a\nb\n  c\n  d\n\n", nil},
+		"  b\n  a\nz\n":        {"  a\n  b\nz\n", nil},
+		"b\n a\n  2\n 1\n":     {"b\n 1\n a\n  2\n", nil},
+		"b\n a\nc\xff\n":       {"b\n a\n", textproc.ErrInvalidUTF8},
+	}
+	internal.CheckRuneProcessor(t, textproc.SortLFTreeI, testcases)
+}